@@ -1,12 +1,20 @@
 package aliyun
 
 import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +22,16 @@ import (
 	"github.com/qor/oss"
 )
 
+var (
+	_ oss.ContextStorage = (*Client)(nil)
+	_ oss.Stater         = (*Client)(nil)
+	_ oss.RangeGetter    = (*Client)(nil)
+	_ oss.Lister         = (*Client)(nil)
+	_ oss.Copier         = (*Client)(nil)
+	_ oss.URLSigner      = (*Client)(nil)
+	_ oss.BatchDeleter   = (*Client)(nil)
+)
+
 // Client Aliyun storage
 type Client struct {
 	*aliyun.Bucket
@@ -30,14 +48,53 @@ type Config struct {
 	ACL           aliyun.ACLType
 	ClientOptions []aliyun.ClientOption
 	UseCname      bool
+
+	// PartSize, Concurrency and CheckpointDir are used when uploading files larger than PartSize in parts with Multipart,
+	// PartSize defaults to 16MB, Concurrency defaults to 4, progress is saved in CheckpointDir if it is set, so interrupted uploads could be resumed
+	PartSize      int64
+	Concurrency   int
+	CheckpointDir string
 }
 
-// New initialize Aliyun storage
+// New initialize Aliyun storage, it panics if the bucket couldn't be opened, use NewWithError to validate config and get the error
 func New(config *Config) *Client {
-	var (
-		err    error
-		client = &Client{Config: config}
-	)
+	client, err := newClient(config)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// NewWithError validate config and initialize Aliyun storage, returns *oss.ConfigError if a field of config is invalid
+func NewWithError(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return newClient(config)
+}
+
+// Validate check fields of config, returns *oss.ConfigError of the first invalid field
+func (config Config) Validate() error {
+	switch {
+	case config.Bucket == "":
+		return oss.NewConfigError("aliyun", "Bucket", "is required")
+	case config.AccessID == "":
+		return oss.NewConfigError("aliyun", "AccessID", "is required")
+	case config.AccessKey == "":
+		return oss.NewConfigError("aliyun", "AccessKey", "is required")
+	case config.ACL != "" && config.ACL != aliyun.ACLPublicRead && config.ACL != aliyun.ACLPublicReadWrite && config.ACL != aliyun.ACLPrivate && config.ACL != aliyun.ACLDefault:
+		return oss.NewConfigError("aliyun", "ACL", fmt.Sprintf("%q isn't a canned ACL", config.ACL))
+	case config.PartSize != 0 && (config.PartSize < aliyun.MinPartSize || config.PartSize > aliyun.MaxPartSize):
+		return oss.NewConfigError("aliyun", "PartSize", "should be between 100KB and 5GB")
+	case config.Concurrency < 0:
+		return oss.NewConfigError("aliyun", "Concurrency", "should not be negative")
+	}
+	return nil
+}
+
+// newClient initialize Aliyun storage, returns error if the bucket couldn't be opened
+func newClient(config *Config) (*Client, error) {
+	client := &Client{Config: config}
 
 	if config.Endpoint == "" {
 		config.Endpoint = "oss-cn-hangzhou.aliyuncs.com"
@@ -52,21 +109,24 @@ func New(config *Config) *Client {
 	}
 
 	Aliyun, err := aliyun.New(config.Endpoint, config.AccessID, config.AccessKey, config.ClientOptions...)
-
-	if err == nil {
-		client.Bucket, err = Aliyun.Bucket(config.Bucket)
-	}
-
 	if err != nil {
-		panic(err)
+		return nil, &oss.ConfigError{Storage: "aliyun", Field: "Endpoint", Reason: "is invalid", Err: err}
 	}
 
-	return client
+	if client.Bucket, err = Aliyun.Bucket(config.Bucket); err != nil {
+		return nil, &oss.ConfigError{Storage: "aliyun", Field: "Bucket", Reason: "is invalid", Err: err}
+	}
+	return client, nil
 }
 
 // Get receive file with given path
 func (client Client) Get(path string) (file *os.File, err error) {
-	readCloser, err := client.GetStream(path)
+	return client.GetContext(context.Background(), path)
+}
+
+// GetContext receive file with given path
+func (client Client) GetContext(ctx context.Context, path string) (file *os.File, err error) {
+	readCloser, err := client.GetStreamContext(ctx, path)
 
 	if err == nil {
 		if file, err = ioutil.TempFile("/tmp", "ali"); err == nil {
@@ -81,16 +141,91 @@ func (client Client) Get(path string) (file *os.File, err error) {
 
 // GetStream get file as stream
 func (client Client) GetStream(path string) (io.ReadCloser, error) {
-	return client.Bucket.GetObject(client.ToRelativePath(path))
+	return client.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream, Aliyun SDK doesn't accept a context, so the context is checked before sending the request and when reading the stream
+func (client Client) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stream, err := client.Bucket.GetObject(client.ToRelativePath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return oss.NewContextReadCloser(ctx, stream), nil
+}
+
+// GetRange get length bytes from offset of the file as stream, negative length reads to the end of the file
+func (client Client) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return client.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream with Range header,
+// standard range behavior is requested so invalid ranges fail instead of returning the whole file
+func (client Client) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := oss.CheckRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	result, err := client.Bucket.DoGetObject(&aliyun.GetObjectRequest{ObjectKey: client.ToRelativePath(path)}, []aliyun.Option{
+		aliyun.NormalizedRange(strings.TrimPrefix(oss.HTTPRange(offset, length), "bytes=")),
+		aliyun.RangeBehavior("standard"),
+	})
+	if err != nil {
+		if err = wrapError(err); errors.Is(err, oss.ErrInvalidRange) && offset == 0 {
+			return http.NoBody, nil // empty file
+		}
+		return nil, err
+	}
+
+	stream := oss.NewContextReadCloser(ctx, result.Response)
+	if result.Response.StatusCode == http.StatusOK {
+		// the whole object is returned if the range is ignored
+		return oss.SkipReadCloser(stream, path, offset, length)
+	}
+	return oss.LimitReadCloser(stream, length), nil
 }
 
 // Put store a reader into given path
 func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutContext(context.Background(), urlPath, reader)
+}
+
+// PutContext store a reader into given path, the reader is streamed and the upload is aborted once the context is done,
+// files larger than PartSize are uploaded in parts with Multipart
+func (client Client) PutContext(ctx context.Context, urlPath string, reader io.Reader) (*oss.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
-	err := client.Bucket.PutObject(client.ToRelativePath(urlPath), reader, aliyun.ACL(client.Config.ACL))
+	if file, ok := reader.(*os.File); ok {
+		if size, ok := oss.ReaderSize(file); ok && size > client.partSize() {
+			return client.uploadFile(ctx, urlPath, file)
+		}
+	}
+
+	fileType, reader, err := oss.DetectContentType(urlPath, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// the SDK streams the reader, readers with known size are limited so it could send the content length, others are sent with chunked transfer encoding
+	size, ok := oss.ReaderSize(reader)
+	reader = oss.NewContextReader(ctx, reader)
+	if ok {
+		reader = io.LimitReader(reader, size)
+	}
+
+	err = client.Bucket.PutObject(client.ToRelativePath(urlPath), reader, aliyun.ObjectACL(client.Config.ACL), aliyun.ContentType(fileType))
 	now := time.Now()
 
 	return &oss.Object{
@@ -98,32 +233,325 @@ func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error)
 		Name:             filepath.Base(urlPath),
 		LastModified:     &now,
 		StorageInterface: client,
-	}, err
+	}, wrapError(err)
+}
+
+// PutFile upload a local file into given path
+func (client Client) PutFile(urlPath string, filePath string) (*oss.Object, error) {
+	return client.PutFileContext(context.Background(), urlPath, filePath)
+}
+
+// PutFileContext upload a local file into given path, files larger than PartSize are uploaded in parts concurrently,
+// if CheckpointDir is set, uploading the same file again resumes the interrupted upload
+func (client Client) PutFileContext(ctx context.Context, urlPath string, filePath string) (*oss.Object, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return client.PutContext(ctx, urlPath, file)
+}
+
+// uploadFile upload file in parts with Multipart, parts are uploaded concurrently and the upload stops once the context is done.
+// If CheckpointDir is set, the upload is saved in it, so it is resumed if the same file is uploaded to the same path again
+func (client Client) uploadFile(ctx context.Context, urlPath string, file *os.File) (*oss.Object, error) {
+	fileType, _, err := oss.DetectContentType(urlPath, file)
+	if err != nil {
+		return nil, err
+	}
+
+	options := oss.MultipartOptions{PartSize: client.partSize(), Concurrency: client.concurrency(), ContentType: fileType}
+	if client.Config.CheckpointDir == "" {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return oss.UploadMultipart(ctx, client.Multipart(), urlPath, file, options)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	checkpointFile := client.checkpointFile(urlPath, file.Name())
+	current := checkpoint{Size: info.Size(), ModTime: info.ModTime()}
+	options.SaveState = func(state oss.MultipartState) error {
+		current.State = state
+		return current.save(checkpointFile)
+	}
+
+	// resume the upload if the file isn't changed, start a new upload if the saved upload is expired
+	if saved, err := loadCheckpoint(checkpointFile); err == nil && saved.Size == current.Size && saved.ModTime.Equal(current.ModTime) && saved.State.Upload != nil {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		options.State = &saved.State
+		object, err := oss.UploadMultipart(ctx, client.Multipart(), urlPath, file, options)
+		if !errors.Is(err, oss.ErrNotExist) {
+			if err == nil {
+				os.Remove(checkpointFile)
+			}
+			return object, err
+		}
+		options.State = nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	object, err := oss.UploadMultipart(ctx, client.Multipart(), urlPath, file, options)
+	if err == nil {
+		os.Remove(checkpointFile)
+	}
+	return object, err
+}
+
+// checkpoint saved state of an upload started by uploadFile, the file's size and modified time are used to check if it is changed
+type checkpoint struct {
+	State   oss.MultipartState `json:"state"`
+	Size    int64              `json:"size"`
+	ModTime time.Time          `json:"mod_time"`
+}
+
+// checkpointFile get checkpoint file of uploading file to urlPath
+func (client Client) checkpointFile(urlPath, filePath string) string {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
+	checksum := md5.Sum([]byte(client.Config.Bucket + "\n" + client.ToRelativePath(urlPath) + "\n" + filePath))
+	return filepath.Join(client.Config.CheckpointDir, hex.EncodeToString(checksum[:])+".json")
+}
+
+// loadCheckpoint load checkpoint from file
+func loadCheckpoint(file string) (*checkpoint, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved checkpoint
+	if err := json.Unmarshal(content, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// save save checkpoint into file
+func (saved checkpoint) save(file string) error {
+	content, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0600)
+}
+
+// partSize size of parts when uploading large files, Aliyun requires at least 100KB
+func (client Client) partSize() int64 {
+	if client.Config.PartSize >= aliyun.MinPartSize {
+		return client.Config.PartSize
+	}
+	return 16 << 20
+}
+
+// concurrency count of parts uploaded concurrently
+func (client Client) concurrency() int {
+	if client.Config.Concurrency > 0 {
+		return client.Config.Concurrency
+	}
+	return 4
+}
+
+// Stat get file's information, uses GetObjectDetailedMeta as GetObjectMeta doesn't return content type and user metadata
+func (client Client) Stat(path string) (*oss.ObjectInfo, error) {
+	return client.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information, the context is checked before sending the request
+func (client Client) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	header, err := client.Bucket.GetObjectDetailedMeta(client.ToRelativePath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	info := &oss.ObjectInfo{
+		Path:         path,
+		Name:         filepath.Base(path),
+		ETag:         strings.Trim(header.Get(aliyun.HTTPHeaderEtag), `"`),
+		ContentType:  header.Get(aliyun.HTTPHeaderContentType),
+		CacheControl: header.Get(aliyun.HTTPHeaderCacheControl),
+		StorageClass: header.Get(aliyun.HTTPHeaderOssStorageClass),
+		Metadata:     map[string]string{},
+	}
+
+	info.Size, _ = strconv.ParseInt(header.Get(aliyun.HTTPHeaderContentLength), 10, 64)
+	if lastModified, err := http.ParseTime(header.Get(aliyun.HTTPHeaderLastModified)); err == nil {
+		info.LastModified = &lastModified
+	}
+
+	metaPrefix := strings.ToLower(aliyun.HTTPHeaderOssMetaPrefix)
+	for key := range header {
+		if name := strings.ToLower(key); strings.HasPrefix(name, metaPrefix) {
+			info.Metadata[strings.TrimPrefix(name, metaPrefix)] = header.Get(key)
+		}
+	}
+
+	return info, nil
 }
 
 // Delete delete file
 func (client Client) Delete(path string) error {
-	return client.Bucket.DeleteObject(client.ToRelativePath(path))
+	return client.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file
+func (client Client) DeleteContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return wrapError(client.Bucket.DeleteObject(client.ToRelativePath(path)))
+}
+
+// DeleteMany delete files in batches
+func (client Client) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return client.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete files in batches of 1000 files with DeleteObjects concurrently, Aliyun only returns deleted files,
+// so files not returned are deleted again one by one to get the reasons of failures
+func (client Client) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	return oss.DeleteInBatches(ctx, paths, oss.BatchOptions{}, func(ctx context.Context, batch []string) (oss.BatchResult, error) {
+		var keys []string
+		for _, path := range batch {
+			keys = append(keys, client.ToRelativePath(path))
+		}
+
+		results, err := client.Bucket.DeleteObjects(keys)
+		if err != nil {
+			return oss.BatchResult{}, wrapError(err)
+		}
+
+		deleted := map[string]bool{}
+		for _, key := range results.DeletedObjects {
+			deleted[key] = true
+		}
+
+		var result oss.BatchResult
+		for i, path := range batch {
+			if deleted[keys[i]] {
+				result.Deleted = append(result.Deleted, path)
+			} else if err := client.DeleteContext(ctx, path); err != nil && !errors.Is(err, oss.ErrNotExist) {
+				result.Failures = append(result.Failures, oss.BatchFailure{Path: path, Err: err})
+			} else {
+				result.Deleted = append(result.Deleted, path)
+			}
+		}
+		return result, nil
+	})
+}
+
+// GetSignedURL generate a pre-signed URL of the file
+func (client Client) GetSignedURL(path string, options oss.URLOptions) (string, error) {
+	return client.GetSignedURLContext(context.Background(), path, options)
+}
+
+// GetSignedURLContext generate a pre-signed URL of the file
+func (client Client) GetSignedURLContext(ctx context.Context, path string, options oss.URLOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	var signOptions []aliyun.Option
+	if options.ResponseContentDisposition != "" {
+		signOptions = append(signOptions, aliyun.ResponseContentDisposition(options.ResponseContentDisposition))
+	}
+	if options.ResponseContentType != "" {
+		signOptions = append(signOptions, aliyun.ResponseContentType(options.ResponseContentType))
+	}
+
+	expires := int64(options.GetExpires() / time.Second)
+	return client.Bucket.SignURL(client.ToRelativePath(path), aliyun.HTTPMethod(options.GetMethod()), expires, signOptions...)
+}
+
+// Copy copy file from "from" to "to"
+func (client Client) Copy(from, to string) error {
+	return client.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to" with CopyObject, the copy gets the configured ACL
+func (client Client) CopyContext(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := client.Bucket.CopyObject(client.ToRelativePath(from), client.ToRelativePath(to), aliyun.ObjectACL(client.Config.ACL))
+	return wrapError(err)
+}
+
+// Move move file from "from" to "to", the file is copied then deleted
+func (client Client) Move(from, to string) error {
+	return client.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to"
+func (client Client) MoveContext(ctx context.Context, from, to string) error {
+	if client.ToRelativePath(from) == client.ToRelativePath(to) {
+		return nil
+	}
+	if err := client.CopyContext(ctx, from, to); err != nil {
+		return err
+	}
+	return client.DeleteContext(ctx, from)
 }
 
 // List list all objects under current path
 func (client Client) List(path string) ([]*oss.Object, error) {
-	var objects []*oss.Object
+	return client.ListContext(context.Background(), path)
+}
 
-	results, err := client.Bucket.ListObjects(aliyun.Prefix(path))
+// ListContext list all objects under current path, pages are listed with markers until the listing isn't truncated
+func (client Client) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
+	var (
+		objects []*oss.Object
+		prefix  string
+		marker  string
+	)
+
+	if path != "" {
+		prefix = strings.Trim(client.ToRelativePath(path), "/") + "/"
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		results, err := client.Bucket.ListObjects(aliyun.Prefix(prefix), aliyun.Marker(marker), aliyun.MaxKeys(1000))
+		if err != nil {
+			return nil, wrapError(err)
+		}
 
-	if err == nil {
 		for _, obj := range results.Objects {
+			lastModified := obj.LastModified
 			objects = append(objects, &oss.Object{
 				Path:             "/" + client.ToRelativePath(obj.Key),
 				Name:             filepath.Base(obj.Key),
-				LastModified:     &obj.LastModified,
+				LastModified:     &lastModified,
 				StorageInterface: client,
 			})
 		}
-	}
 
-	return objects, err
+		if !results.IsTruncated || results.NextMarker == "" {
+			return objects, nil
+		}
+		marker = results.NextMarker
+	}
 }
 
 // GetEndpoint get endpoint, FileSystem's endpoint is /
@@ -145,6 +573,47 @@ func (client Client) GetEndpoint() string {
 
 var urlRegexp = regexp.MustCompile(`(https?:)?//((\w+).)+(\w+)/`)
 
+// ListPage list a page of objects matched options with ListObjectsV2
+func (client Client) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	listOptions := []aliyun.Option{
+		aliyun.Prefix(client.ToRelativePath(options.Prefix)),
+		aliyun.MaxKeys(options.GetMaxKeys()),
+	}
+	if delimiter := options.GetDelimiter(); delimiter != "" {
+		listOptions = append(listOptions, aliyun.Delimiter(delimiter))
+	}
+	if options.StartAfter != "" {
+		listOptions = append(listOptions, aliyun.StartAfter(client.ToRelativePath(options.StartAfter)))
+	}
+	if options.ContinuationToken != "" {
+		listOptions = append(listOptions, aliyun.ContinuationToken(options.ContinuationToken))
+	}
+
+	results, err := client.Bucket.ListObjectsV2(listOptions...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	result := &oss.ListResult{IsTruncated: results.IsTruncated, NextContinuationToken: results.NextContinuationToken}
+	for _, obj := range results.Objects {
+		lastModified := obj.LastModified
+		result.Objects = append(result.Objects, &oss.Object{
+			Path:             "/" + client.ToRelativePath(obj.Key),
+			Name:             filepath.Base(obj.Key),
+			LastModified:     &lastModified,
+			StorageInterface: client,
+		})
+	}
+	for _, commonPrefix := range results.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, "/"+commonPrefix)
+	}
+	return result, nil
+}
+
 // ToRelativePath process path to relative path
 func (client Client) ToRelativePath(urlPath string) string {
 	if urlRegexp.MatchString(urlPath) {
@@ -158,8 +627,31 @@ func (client Client) ToRelativePath(urlPath string) string {
 
 // GetURL get public accessible URL
 func (client Client) GetURL(path string) (url string, err error) {
+	return client.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (client Client) GetURLContext(ctx context.Context, path string) (url string, err error) {
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+
 	if client.Config.ACL == aliyun.ACLPrivate {
-		return client.Bucket.SignURL(client.ToRelativePath(path), aliyun.HTTPGet, 60*60) // 1 hour
+		return client.GetSignedURLContext(ctx, path, oss.URLOptions{})
 	}
 	return path, nil
 }
+
+// wrapError wrap Aliyun errors with oss errors by the response's status code
+func wrapError(err error) error {
+	var serviceErr aliyun.ServiceError
+	if errors.As(err, &serviceErr) {
+		return oss.WrapHTTPError(serviceErr.StatusCode, err)
+	}
+
+	var statusErr aliyun.UnexpectedStatusCodeError
+	if errors.As(err, &statusErr) {
+		return oss.WrapHTTPError(statusErr.Got(), err)
+	}
+	return err
+}
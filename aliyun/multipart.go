@@ -0,0 +1,135 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	aliyun "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/qor/oss"
+)
+
+var _ oss.MultipartUploader = Multipart{}
+
+// Multipart upload files in parts with the client's bucket, it implements oss.MultipartUploader, e.g.
+//
+//	oss.UploadMultipart(ctx, client.Multipart(), "/large.zip", file, oss.MultipartOptions{})
+//
+// Client doesn't implement oss.MultipartUploader itself, as its UploadPart would shadow the embedded Bucket's UploadPart
+type Multipart struct {
+	Client Client
+}
+
+// Multipart get the multipart uploader of the client
+func (client Client) Multipart() Multipart {
+	return Multipart{Client: client}
+}
+
+// InitiateMultipart initiate a multipart upload with InitiateMultipartUpload
+func (multipart Multipart) InitiateMultipart(ctx context.Context, path string, contentType string) (*oss.MultipartUpload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	options := []aliyun.Option{aliyun.ObjectACL(multipart.Client.Config.ACL)}
+	if contentType != "" {
+		options = append(options, aliyun.ContentType(contentType))
+	}
+
+	result, err := multipart.Client.Bucket.InitiateMultipartUpload(multipart.Client.ToRelativePath(path), options...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.MultipartUpload{Path: path, UploadID: result.UploadID, ContentType: contentType}, nil
+}
+
+// UploadPart upload a part
+func (multipart Multipart) UploadPart(ctx context.Context, upload *oss.MultipartUpload, partNumber int, reader io.Reader, size int64) (*oss.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	part, err := multipart.Client.Bucket.UploadPart(multipart.initiateResult(upload), oss.NewContextReader(ctx, reader), size, partNumber)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Part{PartNumber: part.PartNumber, ETag: strings.Trim(part.ETag, `"`), Size: size}, nil
+}
+
+// CompleteMultipart complete a multipart upload with uploaded parts
+func (multipart Multipart) CompleteMultipart(ctx context.Context, upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var uploadParts []aliyun.UploadPart
+	for _, part := range parts {
+		uploadParts = append(uploadParts, aliyun.UploadPart{PartNumber: part.PartNumber, ETag: `"` + part.ETag + `"`})
+	}
+
+	if _, err := multipart.Client.Bucket.CompleteMultipartUpload(multipart.initiateResult(upload), uploadParts); err != nil {
+		return nil, wrapError(err)
+	}
+
+	now := time.Now()
+	return &oss.Object{
+		Path:             upload.Path,
+		Name:             filepath.Base(upload.Path),
+		LastModified:     &now,
+		StorageInterface: multipart.Client,
+	}, nil
+}
+
+// AbortMultipart abort a multipart upload, uploaded parts will be removed
+func (multipart Multipart) AbortMultipart(ctx context.Context, upload *oss.MultipartUpload) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return wrapError(multipart.Client.Bucket.AbortMultipartUpload(multipart.initiateResult(upload)))
+}
+
+// ListParts list uploaded parts of a multipart upload
+func (multipart Multipart) ListParts(ctx context.Context, upload *oss.MultipartUpload) ([]*oss.Part, error) {
+	var (
+		parts   []*oss.Part
+		options []aliyun.Option
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := multipart.Client.Bucket.ListUploadedParts(multipart.initiateResult(upload), options...)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		for _, part := range result.UploadedParts {
+			parts = append(parts, &oss.Part{PartNumber: part.PartNumber, ETag: strings.Trim(part.ETag, `"`), Size: int64(part.Size)})
+		}
+
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker, err := strconv.Atoi(result.NextPartNumberMarker)
+		if err != nil {
+			return nil, fmt.Errorf("aliyun: invalid next part number marker %q: %w", result.NextPartNumberMarker, err)
+		}
+		options = []aliyun.Option{aliyun.PartNumberMarker(marker)}
+	}
+}
+
+func (multipart Multipart) initiateResult(upload *oss.MultipartUpload) aliyun.InitiateMultipartUploadResult {
+	return aliyun.InitiateMultipartUploadResult{
+		Bucket:   multipart.Client.Config.Bucket,
+		Key:      multipart.Client.ToRelativePath(upload.Path),
+		UploadID: upload.UploadID,
+	}
+}
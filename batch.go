@@ -0,0 +1,146 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBatchSize default max objects of a batch request, also the max value accepted by S3, Aliyun and Qiniu
+const DefaultBatchSize = 1000
+
+// BatchDeleter storages that could delete objects in batches, deleting objects that don't exist isn't a failure
+type BatchDeleter interface {
+	DeleteMany(paths []string) (BatchResult, error)
+	DeleteManyContext(ctx context.Context, paths []string) (BatchResult, error)
+}
+
+// BatchResult result of a batch operation, paths are in the same order as requested
+type BatchResult struct {
+	Deleted  []string
+	Failures []BatchFailure
+}
+
+// BatchFailure an object failed in a batch operation
+type BatchFailure struct {
+	Path string
+	Err  error
+}
+
+// Err get an error joined all failures, nil if no failure
+func (result BatchResult) Err() error {
+	var errs []error
+	for _, failure := range result.Failures {
+		errs = append(errs, fmt.Errorf("delete %v: %w", failure.Path, failure.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// BatchOptions options to split objects into batches
+type BatchOptions struct {
+	// BatchSize max objects of a batch, default 1000
+	BatchSize int
+	// Concurrency batches sent at the same time, default 4
+	Concurrency int
+}
+
+// DeleteMany delete objects of paths, deleted in batches if storage implemented BatchDeleter, otherwise deleted one by one with DeleteEach.
+// Failed objects don't stop deleting others, they are returned in result's Failures and the error
+func DeleteMany(ctx context.Context, storage StorageInterface, paths []string) (BatchResult, error) {
+	if deleter, ok := storage.(BatchDeleter); ok {
+		return deleter.DeleteManyContext(ctx, paths)
+	}
+	return DeleteEach(ctx, storage, paths)
+}
+
+// DeleteEach delete objects of paths one by one concurrently, could be used by storages without batch APIs to implement BatchDeleter
+func DeleteEach(ctx context.Context, storage StorageInterface, paths []string) (BatchResult, error) {
+	contextStorage := WithContext(storage)
+	return DeleteInBatches(ctx, paths, BatchOptions{BatchSize: 1}, func(ctx context.Context, batch []string) (BatchResult, error) {
+		if err := contextStorage.DeleteContext(ctx, batch[0]); err != nil && !errors.Is(err, ErrNotExist) {
+			return BatchResult{}, err
+		}
+		return BatchResult{Deleted: batch}, nil
+	})
+}
+
+// DeleteInBatches split paths into batches and delete them with deleteBatch concurrently, used by storages to implement BatchDeleter.
+// deleteBatch reports deleted and failed objects of the batch, objects it doesn't report are failed, all objects of the batch are failed
+// with the error if it returns one
+func DeleteInBatches(ctx context.Context, paths []string, options BatchOptions, deleteBatch func(ctx context.Context, paths []string) (BatchResult, error)) (BatchResult, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultConcurrency
+	}
+
+	var (
+		batches   [][]string
+		waitGroup sync.WaitGroup
+		semaphore = make(chan struct{}, options.Concurrency)
+	)
+	for start := 0; start < len(paths); start += options.BatchSize {
+		batches = append(batches, paths[start:min(start+options.BatchSize, len(paths))])
+	}
+
+	// results are merged in order of batches after all batches are done
+	results := make([]BatchResult, len(batches))
+	for i, batch := range batches {
+		semaphore <- struct{}{}
+		waitGroup.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				waitGroup.Done()
+			}()
+			results[i] = runBatch(ctx, batch, deleteBatch)
+		}()
+	}
+	waitGroup.Wait()
+
+	var result BatchResult
+	for _, batchResult := range results {
+		result.Deleted = append(result.Deleted, batchResult.Deleted...)
+		result.Failures = append(result.Failures, batchResult.Failures...)
+	}
+	return result, result.Err()
+}
+
+// runBatch run deleteBatch and sort its reported objects in order of the batch
+func runBatch(ctx context.Context, batch []string, deleteBatch func(ctx context.Context, paths []string) (BatchResult, error)) BatchResult {
+	var (
+		result   BatchResult
+		reported BatchResult
+		err      = ctx.Err()
+	)
+	if err == nil {
+		reported, err = deleteBatch(ctx, batch)
+	}
+
+	deleted := map[string]bool{}
+	failures := map[string]error{}
+	if err == nil {
+		for _, path := range reported.Deleted {
+			deleted[path] = true
+		}
+		for _, failure := range reported.Failures {
+			failures[failure.Path] = failure.Err
+		}
+	}
+
+	for _, path := range batch {
+		switch {
+		case err != nil:
+			result.Failures = append(result.Failures, BatchFailure{Path: path, Err: err})
+		case failures[path] != nil:
+			result.Failures = append(result.Failures, BatchFailure{Path: path, Err: failures[path]})
+		case deleted[path]:
+			result.Deleted = append(result.Deleted, path)
+		default:
+			result.Failures = append(result.Failures, BatchFailure{Path: path, Err: errors.New("oss: object is not deleted")})
+		}
+	}
+	return result
+}
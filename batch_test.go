@@ -0,0 +1,89 @@
+package oss_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/fault"
+	"github.com/qor/oss/memory"
+)
+
+func TestDeleteMany(t *testing.T) {
+	storage := fault.New(memory.New()).Inject(fault.Rule{Op: fault.OpDelete, Path: "/denied/*", Err: oss.ErrPermission})
+	for _, path := range []string{"/a.txt", "/b.txt", "/denied/c.txt"} {
+		storage.Put(path, strings.NewReader(path))
+	}
+
+	result, err := oss.DeleteMany(context.Background(), storage, []string{"/a.txt", "/denied/c.txt", "/missing.txt", "/b.txt"})
+	if !errors.Is(err, oss.ErrPermission) {
+		t.Errorf("Error should contain failures, but got %v", err)
+	}
+	if !reflect.DeepEqual(result.Deleted, []string{"/a.txt", "/missing.txt", "/b.txt"}) {
+		t.Errorf("Deleted objects should be in requested order, and missing objects are deleted, but got %v", result.Deleted)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Path != "/denied/c.txt" || !errors.Is(result.Failures[0].Err, oss.ErrPermission) {
+		t.Errorf("Denied object should be failed, but got %+v", result.Failures)
+	}
+
+	if objects, _ := storage.List(""); len(objects) != 1 || objects[0].Path != "/denied/c.txt" {
+		t.Errorf("Only denied object should be left, but got %v", objects)
+	}
+}
+
+func TestDeleteInBatches(t *testing.T) {
+	var (
+		paths   []string
+		mutex   sync.Mutex
+		batches [][]string
+	)
+	for i := 0; i < 25; i++ {
+		paths = append(paths, fmt.Sprintf("/%02d.txt", i))
+	}
+
+	result, err := oss.DeleteInBatches(context.Background(), paths, oss.BatchOptions{BatchSize: 10, Concurrency: 2}, func(ctx context.Context, batch []string) (oss.BatchResult, error) {
+		mutex.Lock()
+		batches = append(batches, batch)
+		mutex.Unlock()
+
+		switch batch[0] {
+		case "/00.txt": // report some of the batch
+			return oss.BatchResult{Deleted: batch[1:9], Failures: []oss.BatchFailure{{Path: batch[0], Err: oss.ErrPermission}}}, nil
+		case "/10.txt":
+			return oss.BatchResult{}, oss.ErrConflict
+		}
+		return oss.BatchResult{Deleted: batch}, nil
+	})
+
+	if len(batches) != 3 || len(batches[0])+len(batches[1])+len(batches[2]) != 25 {
+		t.Errorf("Paths should be split into 3 batches, but got %v", batches)
+	}
+	if err == nil || len(result.Deleted) != 8+5 || len(result.Failures) != 2+10 {
+		t.Fatalf("Failed objects should be reported, but got %+v, %v", result, err)
+	}
+
+	if failure := result.Failures[0]; failure.Path != "/00.txt" || !errors.Is(failure.Err, oss.ErrPermission) {
+		t.Errorf("Reported failure should be kept, but got %+v", failure)
+	}
+	if failure := result.Failures[1]; failure.Path != "/09.txt" || failure.Err == nil {
+		t.Errorf("Unreported object should be failed, but got %+v", failure)
+	}
+	for _, failure := range result.Failures[2:] {
+		if !errors.Is(failure.Err, oss.ErrConflict) {
+			t.Errorf("Objects of failed batch should be failed with its error, but got %+v", failure)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result, err := oss.DeleteInBatches(ctx, paths, oss.BatchOptions{}, func(ctx context.Context, batch []string) (oss.BatchResult, error) {
+		return oss.BatchResult{Deleted: batch}, nil
+	}); !errors.Is(err, context.Canceled) || len(result.Failures) != 25 {
+		t.Errorf("All objects should be failed when context is canceled, but got %+v, %v", result, err)
+	}
+}
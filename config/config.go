@@ -0,0 +1,237 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jinzhu/configor"
+	"github.com/qor/oss"
+	"github.com/qor/oss/prefix"
+
+	// register drivers of bundled storages
+	_ "github.com/qor/oss/aliyun"
+	_ "github.com/qor/oss/filesystem"
+	_ "github.com/qor/oss/memory"
+	_ "github.com/qor/oss/qiniu"
+	_ "github.com/qor/oss/s3"
+	_ "github.com/qor/oss/tencent"
+)
+
+// ENVPrefix prefix of environment variables overriding config, e.g. OSS_STORAGES_UPLOADS_BUCKET overrides Bucket of storage uploads
+const ENVPrefix = "OSS"
+
+// Config named storages, e.g. in YAML:
+//
+//	storages:
+//	  uploads:
+//	    type: s3
+//	    accessid: ID
+//	    accesskey: KEY
+//	    region: us-east-1
+//	    bucket: bucket
+//	    prefix: /uploads
+type Config struct {
+	Storages map[string]*Storage
+}
+
+// Storage config of a storage, it is opened with the driver registered with its type
+type Storage struct {
+	// Type scheme of the storage's driver, e.g. file, mem, s3, oss, qiniu, cos, aliases filesystem, memory, aliyun and tencent are also supported
+	Type string
+	// URL open the storage with URL like s3://ID:KEY@bucket?region=us-east-1 instead, other fields except Prefix and Wrappers are ignored
+	URL string
+
+	AccessID  string
+	AccessKey string
+	Region    string
+	Bucket    string
+	ACL       string
+	Endpoint  string
+	// Dir directory of file system storage
+	Dir string
+	// Prefix save files under it, e.g. /uploads
+	Prefix string
+	// Options other query parameters of the driver, e.g. path_style: "true" for S3, see Open of each storage
+	Options map[string]string
+	// Wrappers wrap the storage in order, the last one is the outermost
+	Wrappers []Wrapper
+}
+
+// Wrapper config of a wrapper, e.g. {type: prefix, options: {prefix: /uploads}}
+type Wrapper struct {
+	Type    string
+	Options map[string]string
+}
+
+// WrapFunc wrap storage with options of the wrapper
+type WrapFunc func(storage oss.StorageInterface, options map[string]string) (oss.StorageInterface, error)
+
+var (
+	wrappersMutex sync.RWMutex
+	wrappers      = map[string]WrapFunc{"prefix": wrapPrefix}
+)
+
+// RegisterWrapper register wrapper of type, only prefix is registered by default, the fault wrapper for tests is registered by importing
+// github.com/qor/oss/fault/faultconfig. It panics if the type is registered twice
+func RegisterWrapper(typ string, wrap WrapFunc) {
+	wrappersMutex.Lock()
+	defer wrappersMutex.Unlock()
+
+	if wrap == nil {
+		panic("config: RegisterWrapper wrap is nil")
+	}
+	if _, dup := wrappers[typ]; dup {
+		panic("config: RegisterWrapper called twice for wrapper " + typ)
+	}
+	wrappers[typ] = wrap
+}
+
+// aliases aliases of storage types
+var aliases = map[string]string{"filesystem": "file", "memory": "mem", "aliyun": "oss", "tencent": "cos"}
+
+// Load load config files with configor, then open storages, see LoadConfig
+func Load(files ...string) (map[string]oss.StorageInterface, error) {
+	config, err := LoadConfig(files...)
+	if err != nil {
+		return nil, err
+	}
+	return config.Open()
+}
+
+// LoadConfig load config files with configor, YAML, JSON and TOML files are supported, later files override earlier ones.
+// Fields of storages could be overridden with environment variables like OSS_STORAGES_UPLOADS_BUCKET, storage names are upper cased
+// and other characters than letters and digits are replaced with _, all storages could be set with OSS_STORAGES in YAML or JSON
+func LoadConfig(files ...string) (*Config, error) {
+	config := &Config{}
+	if err := configor.New(&configor.Config{ENVPrefix: ENVPrefix}).Load(config, files...); err != nil {
+		return nil, err
+	}
+
+	for name, storage := range config.Storages {
+		if storage == nil {
+			storage = &Storage{}
+			config.Storages[name] = storage
+		}
+		if err := configor.New(&configor.Config{ENVPrefix: envPrefix(name)}).Load(storage); err != nil {
+			return nil, fmt.Errorf("config: load storage %v: %w", name, err)
+		}
+	}
+	return config, nil
+}
+
+// envPrefix get prefix of environment variables of storage name
+func envPrefix(name string) string {
+	return ENVPrefix + "_STORAGES_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Open open all storages, errors of all failed storages are returned
+func (config *Config) Open() (map[string]oss.StorageInterface, error) {
+	names := make([]string, 0, len(config.Storages))
+	for name := range config.Storages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		errs     []error
+		storages = map[string]oss.StorageInterface{}
+	)
+	for _, name := range names {
+		storage, err := config.Storages[name].Open(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		storages[name] = storage
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return storages, nil
+}
+
+// Open open the storage with its driver, then wrap it with prefix and wrappers, name is used in errors
+func (storage *Storage) Open(name string) (oss.StorageInterface, error) {
+	opened, err := storage.open(name)
+	if err != nil {
+		return nil, fmt.Errorf("config: open storage %v: %w", name, err)
+	}
+	return opened, nil
+}
+
+// open open the storage with URL if it is set, otherwise with URL built from its fields
+func (storage *Storage) open(name string) (oss.StorageInterface, error) {
+	var (
+		opened oss.StorageInterface
+		err    error
+	)
+	if storage.URL != "" {
+		opened, err = oss.Open(storage.URL)
+	} else if storage.Type == "" {
+		err = oss.NewConfigError(name, "Type", "is required")
+	} else {
+		opened, err = oss.OpenURL(storage.toURL())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opened = prefix.Wrap(opened, storage.Prefix)
+
+	wrappersMutex.RLock()
+	defer wrappersMutex.RUnlock()
+	for _, wrapper := range storage.Wrappers {
+		wrap, ok := wrappers[wrapper.Type]
+		if !ok {
+			return nil, oss.NewConfigError(name, "Wrappers", fmt.Sprintf("wrapper %q isn't registered", wrapper.Type))
+		}
+		if opened, err = wrap(opened, wrapper.Options); err != nil {
+			return nil, err
+		}
+	}
+	return opened, nil
+}
+
+// toURL build URL of the storage, which is passed to its driver
+func (storage *Storage) toURL() *url.URL {
+	u := &url.URL{Scheme: strings.ToLower(storage.Type), Host: storage.Bucket}
+	if scheme, ok := aliases[u.Scheme]; ok {
+		u.Scheme = scheme
+	}
+	if u.Scheme == "file" {
+		u.Host, u.Path = "", storage.Dir
+	}
+	if storage.AccessID != "" || storage.AccessKey != "" {
+		u.User = url.UserPassword(storage.AccessID, storage.AccessKey)
+	}
+
+	query := url.Values{}
+	for key, value := range storage.Options {
+		query.Set(key, value)
+	}
+	for key, value := range map[string]string{"region": storage.Region, "acl": storage.ACL, "endpoint": storage.Endpoint} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u
+}
+
+// wrapPrefix save files under options["prefix"]
+func wrapPrefix(storage oss.StorageInterface, options map[string]string) (oss.StorageInterface, error) {
+	return prefix.Wrap(storage, options["prefix"]), nil
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/filesystem"
+	"github.com/qor/oss/memory"
+	"github.com/qor/oss/prefix"
+	"github.com/qor/oss/s3"
+	"github.com/qor/oss/tests/fakes3"
+)
+
+// writeFile write content into file of dir, returns the file's path
+func writeFile(t *testing.T, dir, name, content string) string {
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("No error should happen when write config file, but got %v", err)
+	}
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"storages.yml": `
+storages:
+  uploads:
+    type: filesystem
+    dir: ` + filepath.Join(dir, "uploads") + `
+  cache:
+    type: memory
+    prefix: /cache
+`,
+		"storages.json": `{"storages": {"uploads": {"type": "filesystem", "dir": "` + filepath.ToSlash(filepath.Join(dir, "uploads")) + `"}, "cache": {"type": "memory", "prefix": "/cache"}}}`,
+		"storages.toml": `
+[storages.uploads]
+type = "filesystem"
+dir = "` + filepath.ToSlash(filepath.Join(dir, "uploads")) + `"
+
+[storages.cache]
+type = "memory"
+prefix = "/cache"
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			storages, err := Load(writeFile(t, dir, name, content))
+			if err != nil {
+				t.Fatalf("No error should happen when load storages, but got %v", err)
+			}
+
+			if uploads, ok := storages["uploads"].(*filesystem.FileSystem); !ok || uploads.Base != filepath.Join(dir, "uploads") {
+				t.Errorf("File system storage should be opened with dir, but got %#v", storages["uploads"])
+			}
+			if cache, ok := storages["cache"].(*prefix.Storage); !ok || cache.Prefix != "/cache" {
+				t.Errorf("Memory storage should be wrapped with prefix, but got %#v", storages["cache"])
+			} else if _, ok := cache.Storage.(*memory.Storage); !ok {
+				t.Errorf("Memory storage should be opened, but got %#v", cache.Storage)
+			}
+		})
+	}
+}
+
+func TestLoadWithEnv(t *testing.T) {
+	server := fakes3.New()
+	t.Cleanup(server.Close)
+
+	file := writeFile(t, t.TempDir(), "storages.yml", `
+storages:
+  media-files:
+    type: memory
+    accessid: access_id
+    accesskey: access_key
+    region: us-east-1
+    bucket: fake-bucket
+    acl: private
+    prefix: /media
+    options:
+      path_style: true
+`)
+
+	// switch from memory to S3 with environment variables
+	t.Setenv("OSS_STORAGES_MEDIA_FILES_TYPE", "s3")
+	t.Setenv("OSS_STORAGES_MEDIA_FILES_OPTIONS", "{path_style: true, s3_endpoint: '"+server.URL+"'}")
+
+	storages, err := Load(file)
+	if err != nil {
+		t.Fatalf("No error should happen when load storages, but got %v", err)
+	}
+
+	media := storages["media-files"]
+	if prefixed, ok := media.(*prefix.Storage); !ok {
+		t.Fatalf("Storage should be wrapped with prefix, but got %#v", media)
+	} else if client, ok := prefixed.Storage.(*s3.Client); !ok || client.Config.ACL != "private" || !client.Config.S3ForcePathStyle {
+		t.Fatalf("Storage should be overridden to S3 by environment variables, but got %#v", prefixed.Storage)
+	}
+
+	media.Put("/sample.txt", strings.NewReader("sample"))
+	// keys of path style clients start with /
+	if _, ok := server.Object("fake-bucket", "/media/sample.txt"); !ok {
+		t.Errorf("Object should be saved under prefix")
+	}
+	if stream, err := media.GetStream("/sample.txt"); err != nil {
+		t.Errorf("No error should happen when get file, but got %v", err)
+	} else if content, _ := io.ReadAll(stream); string(content) != "sample" {
+		t.Errorf("File's content should be sample, but got %s", content)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	config := &Config{Storages: map[string]*Storage{
+		"url": {URL: "mem://", Prefix: "/url"},
+		"wrapped": {Type: "mem", Prefix: "/inner", Wrappers: []Wrapper{
+			{Type: "prefix", Options: map[string]string{"prefix": "/outer"}},
+		}},
+	}}
+
+	storages, err := config.Open()
+	if err != nil {
+		t.Fatalf("No error should happen when open storages, but got %v", err)
+	}
+	if prefixed, ok := storages["url"].(*prefix.Storage); !ok || prefixed.Prefix != "/url" {
+		t.Errorf("Storage should be opened with URL and wrapped with prefix, but got %#v", storages["url"])
+	}
+
+	wrapped, ok := storages["wrapped"].(*prefix.Storage)
+	if !ok || wrapped.Prefix != "/outer" {
+		t.Fatalf("Storage should be wrapped in order, but got %#v", storages["wrapped"])
+	}
+	if prefixed, ok := wrapped.Storage.(*prefix.Storage); !ok || prefixed.Prefix != "/inner" {
+		t.Errorf("Storage should be wrapped with prefix first, but got %#v", wrapped.Storage)
+	}
+	if url, _ := wrapped.GetURL("/sample.txt"); url != "/inner/outer/sample.txt" {
+		t.Errorf("Files should be saved under all prefixes, but got %v", url)
+	}
+}
+
+func TestOpenWithError(t *testing.T) {
+	config := &Config{Storages: map[string]*Storage{
+		"untyped":   {},
+		"unknown":   {Type: "ftp"},
+		"invalid":   {Type: "s3", Bucket: "bucket"},
+		"wrapper":   {Type: "mem", Wrappers: []Wrapper{{Type: "cache"}}},
+		"fault":     {Type: "mem", Wrappers: []Wrapper{{Type: "fault"}}},
+		"valid":     {Type: "mem"},
+		"valid-url": {URL: "mem://"},
+	}}
+
+	storages, err := config.Open()
+	if storages != nil || err == nil {
+		t.Fatalf("Storages should not be opened if any storage is invalid, but got %v, %v", storages, err)
+	}
+
+	for _, expected := range []string{
+		"storage untyped: untyped: invalid config Type: is required",
+		`storage unknown: oss: unknown storage "ftp"`,
+		"storage invalid: s3: invalid config Region: is required",
+		`storage wrapper: wrapper: invalid config Wrappers: wrapper "cache" isn't registered`,
+		`storage fault: fault: invalid config Wrappers: wrapper "fault" isn't registered`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Error should contain %q, but got %v", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), "storage valid") {
+		t.Errorf("Valid storages should not fail, but got %v", err)
+	}
+
+	var configErr *oss.ConfigError
+	if !errors.As(err, &configErr) {
+		t.Errorf("Invalid config should fail with oss.ConfigError, but got %v", err)
+	}
+}
+
+func TestRegisterWrapper(t *testing.T) {
+	RegisterWrapper("test", func(storage oss.StorageInterface, options map[string]string) (oss.StorageInterface, error) {
+		return prefix.New(storage, options["dir"]), nil
+	})
+
+	storage, err := (&Storage{Type: "mem", Wrappers: []Wrapper{{Type: "test", Options: map[string]string{"dir": "/test"}}}}).Open("test")
+	if prefixed, ok := storage.(*prefix.Storage); err != nil || !ok || prefixed.Prefix != "/test" {
+		t.Errorf("Storage should be wrapped with registered wrapper, but got %#v, %v", storage, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Registering a wrapper twice should panic")
+		}
+	}()
+	RegisterWrapper("prefix", wrapPrefix)
+}
@@ -0,0 +1,129 @@
+package oss
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// ContextStorage define context aware API to operate storage, the context could be used to cancel requests or apply deadlines
+type ContextStorage interface {
+	StorageInterface
+	GetContext(ctx context.Context, path string) (*os.File, error)
+	GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error)
+	PutContext(ctx context.Context, path string, reader io.Reader) (*Object, error)
+	DeleteContext(ctx context.Context, path string) error
+	ListContext(ctx context.Context, path string) ([]*Object, error)
+	GetURLContext(ctx context.Context, path string) (string, error)
+}
+
+// WithContext lift a storage into ContextStorage, storages implemented ContextStorage will be returned directly,
+// others will check the context before each operation and stop reading streams once the context is done
+func WithContext(storage StorageInterface) ContextStorage {
+	if contextStorage, ok := storage.(ContextStorage); ok {
+		return contextStorage
+	}
+	return contextAdapter{StorageInterface: storage}
+}
+
+type contextAdapter struct {
+	StorageInterface
+}
+
+func (adapter contextAdapter) GetContext(ctx context.Context, path string) (*os.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.Get(path)
+}
+
+func (adapter contextAdapter) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stream, err := adapter.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewContextReadCloser(ctx, stream), nil
+}
+
+func (adapter contextAdapter) PutContext(ctx context.Context, path string, reader io.Reader) (*Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.Put(path, NewContextReader(ctx, reader))
+}
+
+func (adapter contextAdapter) DeleteContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return adapter.Delete(path)
+}
+
+func (adapter contextAdapter) ListContext(ctx context.Context, path string) ([]*Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.List(path)
+}
+
+func (adapter contextAdapter) GetURLContext(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return adapter.GetURL(path)
+}
+
+// NewContextReader wrap reader, reading from it will fail with the context's error once the context is done.
+// The reader is returned as it is if the context never finishes, and it stays an io.ReadSeeker if it was one
+func NewContextReader(ctx context.Context, reader io.Reader) io.Reader {
+	if ctx.Done() == nil {
+		return reader
+	}
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		return contextReadSeeker{contextReader{ctx: ctx, Reader: reader}, seeker}
+	}
+	return contextReader{ctx: ctx, Reader: reader}
+}
+
+// NewContextReadCloser is like NewContextReader, but keeps the Close method of given stream
+func NewContextReadCloser(ctx context.Context, stream io.ReadCloser) io.ReadCloser {
+	if ctx.Done() == nil {
+		return stream
+	}
+	return contextReadCloser{contextReader{ctx: ctx, Reader: stream}, stream}
+}
+
+type contextReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (reader contextReader) Read(p []byte) (int, error) {
+	if err := reader.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return reader.Reader.Read(p)
+}
+
+type contextReadSeeker struct {
+	contextReader
+	seeker io.Seeker
+}
+
+func (reader contextReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return reader.seeker.Seek(offset, whence)
+}
+
+type contextReadCloser struct {
+	contextReader
+	closer io.Closer
+}
+
+func (reader contextReadCloser) Close() error {
+	return reader.closer.Close()
+}
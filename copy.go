@@ -0,0 +1,56 @@
+package oss
+
+import (
+	"context"
+	"strings"
+)
+
+// Copier storages that could copy and move objects on server side without downloading them
+type Copier interface {
+	Copy(from, to string) error
+	CopyContext(ctx context.Context, from, to string) error
+	Move(from, to string) error
+	MoveContext(ctx context.Context, from, to string) error
+}
+
+// Copy copy object from path to another path of the storage, copied on server side if storage implemented Copier,
+// otherwise the object is streamed from the storage then put back
+func Copy(ctx context.Context, storage StorageInterface, from, to string) error {
+	if copier, ok := storage.(Copier); ok {
+		return copier.CopyContext(ctx, from, to)
+	}
+	if samePath(from, to) {
+		return nil
+	}
+
+	contextStorage := WithContext(storage)
+	stream, err := contextStorage.GetStreamContext(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = contextStorage.PutContext(ctx, to, stream)
+	return err
+}
+
+// Move move object from path to another path of the storage, moved on server side if storage implemented Copier,
+// otherwise the object is copied with Copy then deleted
+func Move(ctx context.Context, storage StorageInterface, from, to string) error {
+	if copier, ok := storage.(Copier); ok {
+		return copier.MoveContext(ctx, from, to)
+	}
+	if samePath(from, to) {
+		return nil
+	}
+
+	if err := Copy(ctx, storage, from, to); err != nil {
+		return err
+	}
+	return WithContext(storage).DeleteContext(ctx, from)
+}
+
+// samePath check if two paths point to the same object, moving an object to itself should keep it
+func samePath(from, to string) bool {
+	return strings.TrimPrefix(from, "/") == strings.TrimPrefix(to, "/")
+}
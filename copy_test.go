@@ -0,0 +1,45 @@
+package oss_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/filesystem"
+)
+
+func TestCopyAndMove(t *testing.T) {
+	storage := streamOnly{filesystem.New(t.TempDir())}
+	storage.Put("/a.txt", strings.NewReader("sample"))
+
+	if err := oss.Copy(context.Background(), storage, "/a.txt", "/b/c.txt"); err != nil {
+		t.Errorf("No error should happen when copy file, but got %v", err)
+	}
+
+	if err := oss.Move(context.Background(), storage, "/b/c.txt", "/d.txt"); err != nil {
+		t.Errorf("No error should happen when move file, but got %v", err)
+	}
+
+	if err := oss.Move(context.Background(), storage, "/d.txt", "d.txt"); err != nil {
+		t.Errorf("No error should happen when move file to itself, but got %v", err)
+	}
+
+	for path, exists := range map[string]bool{"/a.txt": true, "/b/c.txt": false, "/d.txt": true} {
+		stream, err := storage.GetStream(path)
+		if !exists {
+			if !errors.Is(err, oss.ErrNotExist) {
+				t.Errorf("%v should not exist, but got %v", path, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("No error should happen when get %v, but got %v", path, err)
+		} else if buffer, _ := ioutil.ReadAll(stream); string(buffer) != "sample" {
+			t.Errorf("%v should contain copied content, but got %v", path, string(buffer))
+		}
+	}
+}
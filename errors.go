@@ -0,0 +1,70 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors returned by storages, backends wrap their native errors with them, so errors could be checked with errors.Is
+// regardless of the storage, the native error is still reachable with errors.As
+var (
+	ErrNotExist   = errors.New("oss: file does not exist")
+	ErrPermission = errors.New("oss: permission denied")
+	ErrConflict   = errors.New("oss: conflict")
+	// ErrInvalidRange returned when reading a range that starts beyond the end of the file
+	ErrInvalidRange = errors.New("oss: invalid range")
+)
+
+// WrapError wrap err with kind, kind should be one of ErrNotExist, ErrPermission, ErrConflict, ErrInvalidRange
+func WrapError(kind error, err error) error {
+	if err == nil || kind == nil || errors.Is(err, kind) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", kind, err)
+}
+
+// WrapHTTPError wrap err with the kind matched given HTTP status code, err is returned as it is if no kind matched
+func WrapHTTPError(statusCode int, err error) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return WrapError(ErrNotExist, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return WrapError(ErrPermission, err)
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return WrapError(ErrConflict, err)
+	case http.StatusRequestedRangeNotSatisfiable:
+		return WrapError(ErrInvalidRange, err)
+	}
+	return err
+}
+
+// ConfigError invalid field of a storage's config, returned by constructors like s3.NewWithError, check it with errors.As
+type ConfigError struct {
+	// Storage name of the storage, e.g. "s3"
+	Storage string
+	// Field name of the invalid field, e.g. "Bucket"
+	Field string
+	// Reason why the field is invalid, e.g. "is required"
+	Reason string
+	// Err underlying error, e.g. error returned by the SDK when validating the field, could be nil
+	Err error
+}
+
+// NewConfigError initialize a ConfigError
+func NewConfigError(storage, field, reason string) *ConfigError {
+	return &ConfigError{Storage: storage, Field: field, Reason: reason}
+}
+
+func (err *ConfigError) Error() string {
+	message := fmt.Sprintf("%v: invalid config %v: %v", err.Storage, err.Field, err.Reason)
+	if err.Err != nil {
+		message += ": " + err.Err.Error()
+	}
+	return message
+}
+
+// Unwrap get the underlying error
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
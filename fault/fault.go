@@ -0,0 +1,414 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qor/oss"
+)
+
+var (
+	_ oss.ContextStorage = (*Storage)(nil)
+	_ oss.Stater         = (*Storage)(nil)
+	_ oss.RangeGetter    = (*Storage)(nil)
+	_ oss.Lister         = (*Storage)(nil)
+	_ oss.Copier         = (*Storage)(nil)
+)
+
+// Operations of storage, used to match calls with rules
+const (
+	OpGet       = "Get"
+	OpGetStream = "GetStream"
+	OpGetRange  = "GetRange"
+	OpPut       = "Put"
+	OpStat      = "Stat"
+	OpDelete    = "Delete"
+	OpList      = "List"
+	OpListPage  = "ListPage"
+	OpCopy      = "Copy"
+	OpMove      = "Move"
+	OpGetURL    = "GetURL"
+)
+
+// ErrTimeout a timeout error could be injected, it is a context.DeadlineExceeded
+var ErrTimeout = fmt.Errorf("fault: timeout: %w", context.DeadlineExceeded)
+
+// Rule a fault injected into matched calls
+type Rule struct {
+	// Op operation to match, e.g. fault.OpPut, empty matches all operations
+	Op string
+	// Path pattern of path to match with path.Match, e.g. "/uploads/*", empty matches all paths
+	Path string
+	// Nth inject the fault into the Nth matched call only, starts from 1, 0 means all matched calls
+	Nth int
+	// Times inject the fault into this many matched calls from the Nth call, 0 means 1 call if Nth is set, otherwise all calls
+	Times int
+
+	// Latency wait before the call, the wait stops once the context is done
+	Latency time.Duration
+	// Err returned without calling the wrapped storage, e.g. oss.ErrNotExist, oss.ErrPermission, fault.ErrTimeout
+	Err error
+	// TruncateAfter streams read by GetStream and GetRange, or readers given to Put, fail with io.ErrUnexpectedEOF after this many bytes
+	TruncateAfter int64
+
+	matched int
+}
+
+// match check if the rule should be applied to the call, matched calls are counted
+func (rule *Rule) match(op, name string) bool {
+	if rule.Op != "" && rule.Op != op {
+		return false
+	}
+	if rule.Path != "" {
+		if ok, _ := path.Match(rule.Path, "/"+strings.TrimPrefix(name, "/")); !ok {
+			return false
+		}
+	}
+
+	rule.matched++
+	times := rule.Times
+	if times == 0 && rule.Nth > 0 {
+		times = 1
+	}
+
+	first := max(rule.Nth, 1)
+	return rule.matched >= first && (times == 0 || rule.matched < first+times)
+}
+
+// Call a recorded call
+type Call struct {
+	Op   string
+	Path string
+	// Target target path of Copy and Move
+	Target string
+	// Err error returned to the caller
+	Err  error
+	Time time.Time
+}
+
+// Storage wrap a storage to inject faults and record calls, it is safe for concurrent use
+type Storage struct {
+	Storage oss.StorageInterface
+
+	mutex sync.Mutex
+	rules []*Rule
+	calls []Call
+}
+
+// New wrap storage to inject faults
+func New(storage oss.StorageInterface) *Storage {
+	return &Storage{Storage: storage}
+}
+
+// Inject add rules, rules are applied in the order they are added, all matched rules' latency is applied,
+// the first matched error is returned
+func (storage *Storage) Inject(rules ...Rule) *Storage {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	for _, rule := range rules {
+		storage.rules = append(storage.rules, &rule)
+	}
+	return storage
+}
+
+// Calls get recorded calls in the order they are made
+func (storage *Storage) Calls() []Call {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+	return append([]Call{}, storage.calls...)
+}
+
+// CallsOf get recorded calls of operation
+func (storage *Storage) CallsOf(op string) (calls []Call) {
+	for _, call := range storage.Calls() {
+		if call.Op == op {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset remove all rules and recorded calls
+func (storage *Storage) Reset() {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+	storage.rules, storage.calls = nil, nil
+}
+
+// fault the fault to inject into a call
+type fault struct {
+	latency       time.Duration
+	err           error
+	truncateAfter int64
+}
+
+// before find rules matched the call, wait for the latency, returns the fault
+func (storage *Storage) before(ctx context.Context, op, name string) fault {
+	var f fault
+
+	storage.mutex.Lock()
+	for _, rule := range storage.rules {
+		if rule.match(op, name) {
+			f.latency += rule.Latency
+			if f.err == nil {
+				f.err = rule.Err
+			}
+			if f.truncateAfter == 0 {
+				f.truncateAfter = rule.TruncateAfter
+			}
+		}
+	}
+	storage.mutex.Unlock()
+
+	if f.latency > 0 {
+		timer := time.NewTimer(f.latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			if f.err == nil {
+				f.err = ctx.Err()
+			}
+		}
+	}
+
+	if f.err == nil {
+		f.err = ctx.Err()
+	}
+	return f
+}
+
+// record record a call
+func (storage *Storage) record(op, name, target string, err error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+	storage.calls = append(storage.calls, Call{Op: op, Path: name, Target: target, Err: err, Time: time.Now()})
+}
+
+// Get receive file with given path
+func (storage *Storage) Get(path string) (*os.File, error) {
+	return storage.GetContext(context.Background(), path)
+}
+
+// GetContext receive file with given path
+func (storage *Storage) GetContext(ctx context.Context, path string) (file *os.File, err error) {
+	defer func() { storage.record(OpGet, path, "", err) }()
+
+	if f := storage.before(ctx, OpGet, path); f.err != nil {
+		return nil, f.err
+	}
+	return oss.WithContext(storage.Storage).GetContext(ctx, path)
+}
+
+// GetStream get file as stream
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	return storage.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream
+func (storage *Storage) GetStreamContext(ctx context.Context, path string) (stream io.ReadCloser, err error) {
+	defer func() { storage.record(OpGetStream, path, "", err) }()
+
+	f := storage.before(ctx, OpGetStream, path)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if stream, err = oss.WithContext(storage.Storage).GetStreamContext(ctx, path); err != nil {
+		return nil, err
+	}
+	return truncateReadCloser(stream, f.truncateAfter), nil
+}
+
+// GetRange get length bytes from offset of the file as stream
+func (storage *Storage) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return storage.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream
+func (storage *Storage) GetRangeContext(ctx context.Context, path string, offset, length int64) (stream io.ReadCloser, err error) {
+	defer func() { storage.record(OpGetRange, path, "", err) }()
+
+	f := storage.before(ctx, OpGetRange, path)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if stream, err = oss.GetRange(ctx, storage.Storage, path, offset, length); err != nil {
+		return nil, err
+	}
+	return truncateReadCloser(stream, f.truncateAfter), nil
+}
+
+// Put store a reader into given path
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutContext(context.Background(), path, reader)
+}
+
+// PutContext store a reader into given path
+func (storage *Storage) PutContext(ctx context.Context, path string, reader io.Reader) (object *oss.Object, err error) {
+	defer func() { storage.record(OpPut, path, "", err) }()
+
+	f := storage.before(ctx, OpPut, path)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if f.truncateAfter > 0 {
+		reader = &truncateReader{Reader: reader, remaining: f.truncateAfter}
+	}
+	return oss.WithContext(storage.Storage).PutContext(ctx, path, reader)
+}
+
+// Stat get file's information, the wrapped storage needs to implement oss.Stater
+func (storage *Storage) Stat(path string) (*oss.ObjectInfo, error) {
+	return storage.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information
+func (storage *Storage) StatContext(ctx context.Context, path string) (info *oss.ObjectInfo, err error) {
+	defer func() { storage.record(OpStat, path, "", err) }()
+
+	if f := storage.before(ctx, OpStat, path); f.err != nil {
+		return nil, f.err
+	}
+
+	stater, ok := storage.Storage.(oss.Stater)
+	if !ok {
+		return nil, fmt.Errorf("fault: %T doesn't implement oss.Stater", storage.Storage)
+	}
+	return stater.StatContext(ctx, path)
+}
+
+// Delete delete file
+func (storage *Storage) Delete(path string) error {
+	return storage.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file
+func (storage *Storage) DeleteContext(ctx context.Context, path string) (err error) {
+	defer func() { storage.record(OpDelete, path, "", err) }()
+
+	if f := storage.before(ctx, OpDelete, path); f.err != nil {
+		return f.err
+	}
+	return oss.WithContext(storage.Storage).DeleteContext(ctx, path)
+}
+
+// List list all objects under current path
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	return storage.ListContext(context.Background(), path)
+}
+
+// ListContext list all objects under current path
+func (storage *Storage) ListContext(ctx context.Context, path string) (objects []*oss.Object, err error) {
+	defer func() { storage.record(OpList, path, "", err) }()
+
+	if f := storage.before(ctx, OpList, path); f.err != nil {
+		return nil, f.err
+	}
+	return oss.WithContext(storage.Storage).ListContext(ctx, path)
+}
+
+// ListPage list a page of objects matched options, rules are matched with the prefix
+func (storage *Storage) ListPage(ctx context.Context, options oss.ListOptions) (result *oss.ListResult, err error) {
+	defer func() { storage.record(OpListPage, options.Prefix, "", err) }()
+
+	if f := storage.before(ctx, OpListPage, options.Prefix); f.err != nil {
+		return nil, f.err
+	}
+	return oss.List(ctx, storage.Storage, options)
+}
+
+// Copy copy file from "from" to "to"
+func (storage *Storage) Copy(from, to string) error {
+	return storage.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to", rules are matched with "from"
+func (storage *Storage) CopyContext(ctx context.Context, from, to string) (err error) {
+	defer func() { storage.record(OpCopy, from, to, err) }()
+
+	if f := storage.before(ctx, OpCopy, from); f.err != nil {
+		return f.err
+	}
+	return oss.Copy(ctx, storage.Storage, from, to)
+}
+
+// Move move file from "from" to "to"
+func (storage *Storage) Move(from, to string) error {
+	return storage.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to", rules are matched with "from"
+func (storage *Storage) MoveContext(ctx context.Context, from, to string) (err error) {
+	defer func() { storage.record(OpMove, from, to, err) }()
+
+	if f := storage.before(ctx, OpMove, from); f.err != nil {
+		return f.err
+	}
+	return oss.Move(ctx, storage.Storage, from, to)
+}
+
+// GetEndpoint get endpoint of the wrapped storage
+func (storage *Storage) GetEndpoint() string {
+	return storage.Storage.GetEndpoint()
+}
+
+// GetURL get public accessible URL
+func (storage *Storage) GetURL(path string) (string, error) {
+	return storage.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (storage *Storage) GetURLContext(ctx context.Context, path string) (url string, err error) {
+	defer func() { storage.record(OpGetURL, path, "", err) }()
+
+	if f := storage.before(ctx, OpGetURL, path); f.err != nil {
+		return "", f.err
+	}
+	return oss.WithContext(storage.Storage).GetURLContext(ctx, path)
+}
+
+// truncateReader fail with io.ErrUnexpectedEOF after remaining bytes are read, if the stream has more data
+type truncateReader struct {
+	io.Reader
+	remaining int64
+}
+
+func (reader *truncateReader) Read(p []byte) (int, error) {
+	if reader.remaining <= 0 {
+		// nothing is cut if the stream ends here
+		var next [1]byte
+		n, err := reader.Reader.Read(next[:])
+		if n > 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	if int64(len(p)) > reader.remaining {
+		p = p[:reader.remaining]
+	}
+	n, err := reader.Reader.Read(p)
+	reader.remaining -= int64(n)
+	return n, err
+}
+
+// truncateReadCloser truncate stream after n bytes, 0 keeps the stream as it is
+func truncateReadCloser(stream io.ReadCloser, n int64) io.ReadCloser {
+	if n <= 0 {
+		return stream
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{&truncateReader{Reader: stream, remaining: n}, stream}
+}
@@ -0,0 +1,84 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/memory"
+	"github.com/qor/oss/tests"
+)
+
+func TestAll(t *testing.T) {
+	tests.TestAll(New(memory.New()), t)
+}
+
+func TestInjectErrors(t *testing.T) {
+	storage := New(memory.New()).Inject(
+		Rule{Op: OpPut, Nth: 2, Err: ErrTimeout},
+		Rule{Op: OpGetStream, Path: "/private/*", Err: oss.ErrPermission},
+	)
+
+	for i, expected := range []error{nil, context.DeadlineExceeded, nil} {
+		if _, err := storage.Put("/private/sample.txt", strings.NewReader("sample")); !errors.Is(err, expected) {
+			t.Errorf("Put %v should return %v, but got %v", i+1, expected, err)
+		}
+	}
+
+	if _, err := storage.GetStream("/private/sample.txt"); !errors.Is(err, oss.ErrPermission) {
+		t.Errorf("Get stream of matched path should return oss.ErrPermission, but got %v", err)
+	}
+
+	if _, err := storage.Get("/private/sample.txt"); err != nil {
+		t.Errorf("Get file should not be affected by rules of other operations, but got %v", err)
+	}
+
+	calls := storage.CallsOf(OpPut)
+	if len(calls) != 3 || !errors.Is(calls[1].Err, ErrTimeout) || calls[0].Path != "/private/sample.txt" {
+		t.Errorf("Calls should be recorded, but got %+v", calls)
+	}
+}
+
+func TestInjectLatency(t *testing.T) {
+	storage := New(memory.New()).Inject(Rule{Op: OpList, Latency: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := storage.ListContext(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Latency should be stopped by context, but got %v", err)
+	}
+}
+
+func TestTruncateStream(t *testing.T) {
+	storage := New(memory.New()).Inject(Rule{Op: OpGetStream, TruncateAfter: 3})
+	storage.Put("/sample.txt", strings.NewReader("sample"))
+
+	stream, err := storage.GetStream("/sample.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when get stream, but got %v", err)
+	}
+
+	if content, err := ioutil.ReadAll(stream); err != io.ErrUnexpectedEOF || string(content) != "sam" {
+		t.Errorf("Stream should be truncated after 3 bytes, but got %q, %v", content, err)
+	}
+}
+
+func TestTruncateCompleteStream(t *testing.T) {
+	storage := New(memory.New()).Inject(Rule{Op: OpGetStream, TruncateAfter: 6})
+	storage.Put("/sample.txt", strings.NewReader("sample"))
+
+	stream, err := storage.GetStream("/sample.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when get stream, but got %v", err)
+	}
+
+	if content, err := ioutil.ReadAll(stream); err != nil || string(content) != "sample" {
+		t.Errorf("Stream as long as TruncateAfter should not be truncated, but got %q, %v", content, err)
+	}
+}
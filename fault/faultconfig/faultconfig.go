@@ -0,0 +1,60 @@
+// Package faultconfig register the fault wrapper to config, so faults could be injected into storages loaded from config in tests, e.g.
+//
+//	import _ "github.com/qor/oss/fault/faultconfig"
+//
+// It should only be imported by tests, wrapped storages don't implement optional interfaces like oss.BatchDeleter and oss.URLSigner
+package faultconfig
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/config"
+	"github.com/qor/oss/fault"
+)
+
+func init() {
+	config.RegisterWrapper("fault", Wrap)
+}
+
+// faultErrors errors could be injected with the fault wrapper
+var faultErrors = map[string]error{
+	"not_exist":     oss.ErrNotExist,
+	"permission":    oss.ErrPermission,
+	"conflict":      oss.ErrConflict,
+	"invalid_range": oss.ErrInvalidRange,
+	"timeout":       fault.ErrTimeout,
+}
+
+// Wrap inject a fault into the storage, options are fields of fault.Rule: op, path, nth, times, latency, error and truncate_after,
+// error should be one of not_exist, permission, conflict, invalid_range and timeout
+func Wrap(storage oss.StorageInterface, options map[string]string) (oss.StorageInterface, error) {
+	rule := fault.Rule{Op: options["op"], Path: options["path"]}
+
+	var err error
+	if value := options["error"]; value != "" {
+		if rule.Err = faultErrors[value]; rule.Err == nil {
+			return nil, oss.NewConfigError("fault", "error", fmt.Sprintf("%q isn't a known error", value))
+		}
+	}
+	if value := options["latency"]; value != "" {
+		if rule.Latency, err = time.ParseDuration(value); err != nil {
+			return nil, &oss.ConfigError{Storage: "fault", Field: "latency", Reason: "is not a duration", Err: err}
+		}
+	}
+	for key, field := range map[string]*int{"nth": &rule.Nth, "times": &rule.Times} {
+		if value := options[key]; value != "" {
+			if *field, err = strconv.Atoi(value); err != nil {
+				return nil, &oss.ConfigError{Storage: "fault", Field: key, Reason: "is not a number", Err: err}
+			}
+		}
+	}
+	if value := options["truncate_after"]; value != "" {
+		if rule.TruncateAfter, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, &oss.ConfigError{Storage: "fault", Field: "truncate_after", Reason: "is not a number", Err: err}
+		}
+	}
+	return fault.New(storage).Inject(rule), nil
+}
@@ -0,0 +1,49 @@
+package faultconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/config"
+	"github.com/qor/oss/fault"
+	"github.com/qor/oss/prefix"
+)
+
+func TestWrap(t *testing.T) {
+	storage, err := (&config.Storage{Type: "mem", Prefix: "/inner", Wrappers: []config.Wrapper{
+		{Type: "fault", Options: map[string]string{"op": fault.OpPut, "error": "permission", "latency": "1ms"}},
+	}}).Open("wrapped")
+	if err != nil {
+		t.Fatalf("No error should happen when open storage with fault wrapper, but got %v", err)
+	}
+
+	wrapped, ok := storage.(*fault.Storage)
+	if !ok {
+		t.Fatalf("Storage should be wrapped with fault, but got %#v", storage)
+	}
+	if _, ok := wrapped.Storage.(*prefix.Storage); !ok {
+		t.Errorf("Storage should be wrapped with prefix first, but got %#v", wrapped.Storage)
+	}
+
+	start := time.Now()
+	if _, err := wrapped.Put("/sample.txt", strings.NewReader("sample")); !errors.Is(err, oss.ErrPermission) || time.Since(start) < time.Millisecond {
+		t.Errorf("Fault should be injected, but got %v", err)
+	}
+}
+
+func TestWrapWithError(t *testing.T) {
+	for field, options := range map[string]map[string]string{
+		"error":          {"error": "boom"},
+		"latency":        {"latency": "soon"},
+		"nth":            {"nth": "second"},
+		"truncate_after": {"truncate_after": "1KB"},
+	} {
+		var configErr *oss.ConfigError
+		if _, err := Wrap(nil, options); !errors.As(err, &configErr) || configErr.Field != field {
+			t.Errorf("Invalid %v should fail with oss.ConfigError of it, but got %v", field, err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package filesystem
+
+import (
+	"net/url"
+	"path/filepath"
+
+	"github.com/qor/oss"
+)
+
+func init() {
+	oss.Register("file", Open)
+}
+
+// Open initialize FileSystem storage with URL like file:///var/uploads, it is registered as the file driver,
+// relative directories could be set like file://public/uploads
+func Open(u *url.URL) (oss.StorageInterface, error) {
+	if u.Host == "" && u.Path == "" {
+		return nil, oss.NewConfigError("filesystem", "Base", "is required")
+	}
+	return NewWithError(filepath.FromSlash(u.Host + u.Path))
+}
@@ -1,9 +1,13 @@
 package filesystem
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math/rand/v2"
+	"mime"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,12 +15,21 @@ import (
 	"github.com/qor/oss"
 )
 
+var (
+	_ oss.ContextStorage = FileSystem{}
+	_ oss.Stater         = FileSystem{}
+	_ oss.RangeGetter    = FileSystem{}
+	_ oss.Lister         = FileSystem{}
+	_ oss.Copier         = FileSystem{}
+	_ oss.BatchDeleter   = FileSystem{}
+)
+
 // FileSystem file system storage
 type FileSystem struct {
 	Base string
 }
 
-// New initialize FileSystem storage
+// New initialize FileSystem storage, use NewWithError to get the error if base is invalid
 func New(base string) *FileSystem {
 	absbase, err := filepath.Abs(base)
 	if err != nil {
@@ -25,6 +38,22 @@ func New(base string) *FileSystem {
 	return &FileSystem{Base: absbase}
 }
 
+// NewWithError initialize FileSystem storage, returns *oss.ConfigError if base couldn't be resolved or isn't a directory,
+// base doesn't need to exist, it is created when saving files
+func NewWithError(base string) (*FileSystem, error) {
+	absbase, err := filepath.Abs(base)
+	if err != nil {
+		return nil, &oss.ConfigError{Storage: "filesystem", Field: "Base", Reason: "couldn't be resolved", Err: err}
+	}
+
+	if info, err := os.Stat(absbase); err == nil && !info.IsDir() {
+		return nil, oss.NewConfigError("filesystem", "Base", fmt.Sprintf("%v isn't a directory", absbase))
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, &oss.ConfigError{Storage: "filesystem", Field: "Base", Reason: "couldn't be accessed", Err: err}
+	}
+	return &FileSystem{Base: absbase}, nil
+}
+
 // GetFullPath get full path from absolute/relative path
 func (fileSystem FileSystem) GetFullPath(path string) string {
 	fullpath := path
@@ -36,55 +65,261 @@ func (fileSystem FileSystem) GetFullPath(path string) string {
 
 // Get receive file with given path
 func (fileSystem FileSystem) Get(path string) (*os.File, error) {
-	return os.Open(fileSystem.GetFullPath(path))
+	return fileSystem.GetContext(context.Background(), path)
+}
+
+// GetContext receive file with given path, the context is checked before opening the file
+func (fileSystem FileSystem) GetContext(ctx context.Context, path string) (*os.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fileSystem.GetFullPath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return file, nil
 }
 
 // GetStream get file as stream
 func (fileSystem FileSystem) GetStream(path string) (io.ReadCloser, error) {
-	return os.Open(fileSystem.GetFullPath(path))
+	return fileSystem.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream, the context is checked before opening the file
+func (fileSystem FileSystem) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fileSystem.GetFullPath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return file, nil
+}
+
+// GetRange get length bytes from offset of the file as stream, negative length reads to the end of the file
+func (fileSystem FileSystem) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return fileSystem.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream, the file is seeked to offset and limited to length
+func (fileSystem FileSystem) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.CheckRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	file, err := fileSystem.GetContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err == nil && offset > 0 && offset >= info.Size() {
+		err = oss.WrapError(oss.ErrInvalidRange, fmt.Errorf("offset %d is beyond the end of %s", offset, path))
+	}
+	if err == nil {
+		_, err = file.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return oss.LimitReadCloser(file, length), nil
 }
 
 // Put store a reader into given path
 func (fileSystem FileSystem) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return fileSystem.PutContext(context.Background(), path, reader)
+}
+
+// PutContext store a reader into given path, reading from the reader stops once the context is done.
+// Content is streamed into a temporary file which replaces the target file when finished, so the file could be updated with its own content
+func (fileSystem FileSystem) PutContext(ctx context.Context, path string, reader io.Reader) (*oss.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var (
 		fullpath = fileSystem.GetFullPath(path)
 		err      = os.MkdirAll(filepath.Dir(fullpath), os.ModePerm)
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
-	buf := bytes.NewBuffer([]byte{})
-	if _, err = io.Copy(buf, reader); err != nil {
+
+	dst, err := createTemp(fullpath)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer os.Remove(dst.Name())
+
+	_, err = io.Copy(dst, oss.NewContextReader(ctx, reader))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(dst.Name(), fullpath)
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Object{Path: path, Name: filepath.Base(path), StorageInterface: fileSystem}, nil
+}
+
+// createTemp create a temporary file in the directory of fullpath, it is renamed to fullpath after written.
+// It has the mode of the existing file, or 0666 with umask applied like os.Create
+func createTemp(fullpath string) (*os.File, error) {
+	mode := os.FileMode(0666)
+	if info, err := os.Stat(fullpath); err == nil && info.Mode().IsRegular() {
+		mode = info.Mode().Perm()
+	}
+
+	for {
+		name := filepath.Join(filepath.Dir(fullpath), fmt.Sprintf(".%s.%d", filepath.Base(fullpath), rand.Uint64()))
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, mode)
+		if os.IsExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// umask is applied when the file is created, keep the existing file's mode as it is
+		if mode != 0666 {
+			if err = file.Chmod(mode); err != nil {
+				file.Close()
+				os.Remove(name)
+				return nil, err
+			}
+		}
+		return file, nil
+	}
+}
+
+// Stat get file's information, file system doesn't have ETag and metadata
+func (fileSystem FileSystem) Stat(path string) (*oss.ObjectInfo, error) {
+	return fileSystem.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information, the context is checked before reading the file's information
+func (fileSystem FileSystem) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	dst, err := os.Create(fullpath)
+	fullpath := fileSystem.GetFullPath(path)
 
-	if err == nil {
-		_, err = io.Copy(dst, buf)
+	info, err := os.Stat(fullpath)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return &oss.Object{Path: path, Name: filepath.Base(path), StorageInterface: fileSystem}, err
+	if info.IsDir() {
+		return nil, oss.WrapError(oss.ErrNotExist, &os.PathError{Op: "stat", Path: fullpath, Err: fmt.Errorf("is a directory")})
+	}
+
+	modTime := info.ModTime()
+	return &oss.ObjectInfo{
+		Path:         path,
+		Name:         info.Name(),
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(fullpath)),
+		LastModified: &modTime,
+	}, nil
 }
 
 // Delete delete file
 func (fileSystem FileSystem) Delete(path string) error {
-	return os.Remove(fileSystem.GetFullPath(path))
+	return fileSystem.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file, the context is checked before removing the file
+func (fileSystem FileSystem) DeleteContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return wrapError(os.Remove(fileSystem.GetFullPath(path)))
+}
+
+// DeleteMany delete files
+func (fileSystem FileSystem) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return fileSystem.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete files one by one concurrently, files don't exist are deleted
+func (fileSystem FileSystem) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	return oss.DeleteEach(ctx, fileSystem, paths)
+}
+
+// Copy copy file from "from" to "to"
+func (fileSystem FileSystem) Copy(from, to string) error {
+	return fileSystem.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to", the content is copied into a temporary file then renamed to "to",
+// so the copy is independent of the source
+func (fileSystem FileSystem) CopyContext(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	source, target := fileSystem.GetFullPath(from), fileSystem.GetFullPath(to)
+	if source == target {
+		return nil
+	}
+
+	file, err := fileSystem.GetContext(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fileSystem.PutContext(ctx, to, file)
+	return err
+}
+
+// Move move file from "from" to "to" with os.Rename
+func (fileSystem FileSystem) Move(from, to string) error {
+	return fileSystem.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to" with os.Rename
+func (fileSystem FileSystem) MoveContext(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	target := fileSystem.GetFullPath(to)
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return wrapError(err)
+	}
+	return wrapError(os.Rename(fileSystem.GetFullPath(from), target))
 }
 
 // List list all objects under current path
 func (fileSystem FileSystem) List(path string) ([]*oss.Object, error) {
+	return fileSystem.ListContext(context.Background(), path)
+}
+
+// ListContext list all objects under current path, walking stops once the context is done
+func (fileSystem FileSystem) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
 	var (
 		objects  []*oss.Object
 		fullpath = fileSystem.GetFullPath(path)
 	)
 
-	filepath.Walk(fullpath, func(path string, info os.FileInfo, err error) error {
+	walkErr := filepath.Walk(fullpath, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
 		if path == fullpath {
 			return nil
 		}
@@ -101,9 +336,27 @@ func (fileSystem FileSystem) List(path string) ([]*oss.Object, error) {
 		return nil
 	})
 
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
 	return objects, nil
 }
 
+// ListPage list a page of objects matched options, files under the directory of the prefix are walked then paginated
+func (fileSystem FileSystem) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	dir := "/" + strings.TrimPrefix(options.Prefix, "/")
+	if !strings.HasSuffix(dir, "/") {
+		dir = filepath.Dir(dir)
+	}
+
+	objects, err := fileSystem.ListContext(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return oss.Paginate(objects, options), nil
+}
+
 // GetEndpoint get endpoint, FileSystem's endpoint is /
 func (fileSystem FileSystem) GetEndpoint() string {
 	return "/"
@@ -111,5 +364,26 @@ func (fileSystem FileSystem) GetEndpoint() string {
 
 // GetURL get public accessible URL
 func (fileSystem FileSystem) GetURL(path string) (url string, err error) {
+	return fileSystem.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (fileSystem FileSystem) GetURLContext(ctx context.Context, path string) (url string, err error) {
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
 	return path, nil
 }
+
+// wrapError wrap os errors with oss errors
+func wrapError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return oss.WrapError(oss.ErrNotExist, err)
+	case errors.Is(err, fs.ErrPermission):
+		return oss.WrapError(oss.ErrPermission, err)
+	case errors.Is(err, fs.ErrExist):
+		return oss.WrapError(oss.ErrConflict, err)
+	}
+	return err
+}
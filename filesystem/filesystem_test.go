@@ -1,8 +1,14 @@
 package filesystem
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/qor/oss"
 	"github.com/qor/oss/tests"
 )
 
@@ -10,3 +16,111 @@ func TestAll(t *testing.T) {
 	fileSystem := New("/tmp")
 	tests.TestAll(fileSystem, t)
 }
+
+func TestContext(t *testing.T) {
+	fileSystem := New("/tmp")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := fileSystem.PutContext(ctx, "/context/sample.txt", strings.NewReader("sample")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Put with canceled context should fail with context.Canceled, but got %v", err)
+	}
+
+	if _, err := oss.WithContext(fileSystem).ListContext(ctx, "/context"); !errors.Is(err, context.Canceled) {
+		t.Errorf("List with canceled context should fail with context.Canceled, but got %v", err)
+	}
+}
+
+func TestPutFileMode(t *testing.T) {
+	fileSystem := New(t.TempDir())
+
+	// new files are created with umask applied like os.Create
+	created, _ := os.Create(fileSystem.GetFullPath("/created.txt"))
+	created.Close()
+	expected, _ := os.Stat(created.Name())
+
+	fileSystem.Put("/sample.txt", strings.NewReader("sample"))
+	if info, err := os.Stat(fileSystem.GetFullPath("/sample.txt")); err != nil {
+		t.Errorf("No error should happen when stat file, but got %v", err)
+	} else if info.Mode() != expected.Mode() {
+		t.Errorf("New file's mode should be %v, but got %v", expected.Mode(), info.Mode())
+	}
+
+	// replaced files keep their mode
+	os.Chmod(fileSystem.GetFullPath("/sample.txt"), 0600)
+	fileSystem.Put("/sample.txt", strings.NewReader("updated"))
+	if info, err := os.Stat(fileSystem.GetFullPath("/sample.txt")); err != nil {
+		t.Errorf("No error should happen when stat file, but got %v", err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Replaced file's mode should be kept, but got %v", info.Mode())
+	}
+}
+
+func TestCopy(t *testing.T) {
+	fileSystem := New(t.TempDir())
+	fileSystem.Put("/a.txt", strings.NewReader("sample"))
+
+	if err := fileSystem.Copy("/a.txt", "/b.txt"); err != nil {
+		t.Fatalf("No error should happen when copy file, but got %v", err)
+	}
+
+	// copied file is independent, writing it in place should keep the source file
+	os.WriteFile(fileSystem.GetFullPath("/b.txt"), []byte("updated"), 0644)
+	os.Chmod(fileSystem.GetFullPath("/b.txt"), 0600)
+	if content, _ := os.ReadFile(fileSystem.GetFullPath("/a.txt")); string(content) != "sample" {
+		t.Errorf("Source file should not be changed after update copied file, but got %v", string(content))
+	}
+	if info, _ := os.Stat(fileSystem.GetFullPath("/a.txt")); info.Mode().Perm() == 0600 {
+		t.Errorf("Source file's mode should not be changed after chmod copied file")
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	fileSystem := New(t.TempDir())
+	for _, path := range []string{"/a.txt", "/b.txt", "/dir/c.txt"} {
+		fileSystem.Put(path, strings.NewReader("sample"))
+	}
+
+	// non-empty directories couldn't be removed
+	result, err := fileSystem.DeleteMany([]string{"/a.txt", "/dir", "/missing.txt", "/b.txt"})
+	if err == nil || len(result.Failures) != 1 || result.Failures[0].Path != "/dir" {
+		t.Errorf("Non-empty directory should be failed, but got %+v, %v", result.Failures, err)
+	}
+	if strings.Join(result.Deleted, ",") != "/a.txt,/missing.txt,/b.txt" {
+		t.Errorf("Files should be deleted, but got %v", result.Deleted)
+	}
+
+	if objects, _ := fileSystem.List(""); len(objects) != 1 || objects[0].Path != "/dir/c.txt" {
+		t.Errorf("Only files in directory should be left, but got %v", objects)
+	}
+}
+
+func TestNewWithError(t *testing.T) {
+	base := t.TempDir()
+	os.WriteFile(base+"/file", []byte("file"), 0644)
+
+	var configErr *oss.ConfigError
+	if _, err := NewWithError(base + "/file"); !errors.As(err, &configErr) || configErr.Field != "Base" {
+		t.Errorf("Base should be a directory, but got %v", err)
+	}
+
+	if fileSystem, err := NewWithError(base + "/missing"); err != nil || fileSystem.Base != base+"/missing" {
+		t.Errorf("Missing base should be allowed, but got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	base := t.TempDir()
+	storage, err := oss.Open("file://" + filepath.ToSlash(base))
+	if err != nil {
+		t.Fatalf("No error should happen when open file system storage with URL, but got %v", err)
+	}
+	if fileSystem, ok := storage.(*FileSystem); !ok || fileSystem.Base != base {
+		t.Errorf("Base should be the URL's path, but got %#v", storage)
+	}
+
+	var configErr *oss.ConfigError
+	if _, err := oss.Open("file://"); !errors.As(err, &configErr) || configErr.Field != "Base" {
+		t.Errorf("URL without path should fail with oss.ConfigError, but got %v", err)
+	}
+}
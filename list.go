@@ -0,0 +1,176 @@
+package oss
+
+import (
+	"context"
+	"iter"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxKeys default max keys of a list page, also the max value accepted by most storages
+const DefaultMaxKeys = 1000
+
+// Lister storages that could list objects page by page
+type Lister interface {
+	ListPage(ctx context.Context, options ListOptions) (*ListResult, error)
+}
+
+// ListOptions options to list objects
+type ListOptions struct {
+	// Prefix list objects whose path starts with it, e.g. "/products/"
+	Prefix string
+	// Delimiter group objects contain it after the prefix into CommonPrefixes, defaults to "/" unless Recursive
+	Delimiter string
+	// StartAfter list objects whose path is after it in lexicographical order
+	StartAfter string
+	// MaxKeys max number of objects and common prefixes of a page, defaults to 1000
+	MaxKeys int
+	// Recursive list all objects under the prefix without grouping them
+	Recursive bool
+	// ContinuationToken token returned by last page to continue the listing
+	ContinuationToken string
+}
+
+// GetDelimiter get delimiter used to group objects, empty when listing recursively
+func (options ListOptions) GetDelimiter() string {
+	if options.Recursive {
+		return ""
+	}
+	if options.Delimiter == "" {
+		return "/"
+	}
+	return options.Delimiter
+}
+
+// GetMaxKeys get max keys of a page
+func (options ListOptions) GetMaxKeys() int {
+	if options.MaxKeys <= 0 || options.MaxKeys > DefaultMaxKeys {
+		return DefaultMaxKeys
+	}
+	return options.MaxKeys
+}
+
+// ListResult a page of objects, common prefixes are paths end with the delimiter, e.g. "/products/images/"
+type ListResult struct {
+	Objects               []*Object
+	CommonPrefixes        []string
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List list a page of objects, storages don't implement Lister are listed with List then paginated in memory
+func List(ctx context.Context, storage StorageInterface, options ListOptions) (*ListResult, error) {
+	if lister, ok := storage.(Lister); ok {
+		return lister.ListPage(ctx, options)
+	}
+
+	// list the directory contains the prefix
+	dir := "/" + strings.TrimPrefix(options.Prefix, "/")
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+
+	objects, err := WithContext(storage).ListContext(ctx, strings.Trim(dir, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return Paginate(objects, options), nil
+}
+
+// Objects iterate all objects matched options, pages are listed when needed, common prefixes are yielded as
+// objects whose path ends with the delimiter. Iteration stops after the first error
+func Objects(ctx context.Context, storage StorageInterface, options ListOptions) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		for {
+			result, err := List(ctx, storage, options)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			var prefixes []*Object
+			for _, prefix := range result.CommonPrefixes {
+				prefixes = append(prefixes, &Object{
+					Path:             prefix,
+					Name:             path.Base(prefix),
+					StorageInterface: storage,
+				})
+			}
+
+			// yield objects and common prefixes in lexicographical order like they are listed
+			objects := append(prefixes, result.Objects...)
+			sort.SliceStable(objects, func(i, j int) bool { return objects[i].Path < objects[j].Path })
+			for _, object := range objects {
+				if !yield(object, nil) {
+					return
+				}
+			}
+
+			if !result.IsTruncated || result.NextContinuationToken == "" {
+				return
+			}
+			options.ContinuationToken = result.NextContinuationToken
+		}
+	}
+}
+
+// Paginate get a page of objects matched options from all objects, could be used by storages can't list objects in pages.
+// The continuation token is the path of last object or common prefix of the page
+func Paginate(objects []*Object, options ListOptions) *ListResult {
+	var (
+		result     = &ListResult{}
+		delimiter  = options.GetDelimiter()
+		maxKeys    = options.GetMaxKeys()
+		prefix     = "/" + strings.TrimPrefix(options.Prefix, "/")
+		after      = options.ContinuationToken
+		startAfter string
+	)
+
+	// objects are skipped by StartAfter before they are grouped, so a common prefix contains objects after it is still listed
+	if after == "" && options.StartAfter != "" {
+		startAfter = "/" + strings.TrimPrefix(options.StartAfter, "/")
+	}
+
+	sorted := make([]*Object, len(objects))
+	copy(sorted, objects)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	for _, object := range sorted {
+		objectPath := "/" + strings.TrimPrefix(object.Path, "/")
+		if !strings.HasPrefix(objectPath, prefix) || objectPath <= startAfter {
+			continue
+		}
+
+		commonPrefix := ""
+		if delimiter != "" {
+			if idx := strings.Index(objectPath[len(prefix):], delimiter); idx >= 0 {
+				commonPrefix = objectPath[:len(prefix)+idx+len(delimiter)]
+			}
+		}
+
+		if commonPrefix != "" {
+			if commonPrefix <= after {
+				continue
+			}
+		} else if objectPath <= after {
+			continue
+		}
+
+		if len(result.Objects)+len(result.CommonPrefixes) >= maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = after
+			return result
+		}
+
+		if commonPrefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+			after = commonPrefix
+		} else {
+			result.Objects = append(result.Objects, object)
+			after = objectPath
+		}
+	}
+
+	return result
+}
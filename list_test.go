@@ -0,0 +1,69 @@
+package oss_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/filesystem"
+)
+
+func TestPaginate(t *testing.T) {
+	var objects []*oss.Object
+	for _, path := range []string{"/b/2.txt", "/a.txt", "/b/1.txt", "/b-c.txt", "/c/d/e.txt", "/d.txt"} {
+		objects = append(objects, &oss.Object{Path: path})
+	}
+
+	paths := func(result *oss.ListResult) (paths []string) {
+		for _, object := range result.Objects {
+			paths = append(paths, object.Path)
+		}
+		return append(paths, result.CommonPrefixes...)
+	}
+
+	result := oss.Paginate(objects, oss.ListOptions{MaxKeys: 3})
+	if got := paths(result); !reflect.DeepEqual(got, []string{"/a.txt", "/b-c.txt", "/b/"}) || !result.IsTruncated {
+		t.Errorf("First page should be grouped by delimiter, but got %v", got)
+	}
+
+	result = oss.Paginate(objects, oss.ListOptions{MaxKeys: 3, ContinuationToken: result.NextContinuationToken})
+	if got := paths(result); !reflect.DeepEqual(got, []string{"/d.txt", "/c/"}) || result.IsTruncated {
+		t.Errorf("Second page should continue after the token, but got %v", got)
+	}
+
+	result = oss.Paginate(objects, oss.ListOptions{Prefix: "/b", StartAfter: "/b-c.txt", Recursive: true})
+	if got := paths(result); !reflect.DeepEqual(got, []string{"/b/1.txt", "/b/2.txt"}) {
+		t.Errorf("Recursive list should return objects after start after, but got %v", got)
+	}
+
+	result = oss.Paginate(objects, oss.ListOptions{StartAfter: "/b/1.txt"})
+	if got := paths(result); !reflect.DeepEqual(got, []string{"/d.txt", "/b/", "/c/"}) {
+		t.Errorf("Common prefix contains objects after start after should be listed, but got %v", got)
+	}
+
+	result = oss.Paginate(objects, oss.ListOptions{StartAfter: "/b/2.txt"})
+	if got := paths(result); !reflect.DeepEqual(got, []string{"/d.txt", "/c/"}) {
+		t.Errorf("Common prefix without objects after start after should be skipped, but got %v", got)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	storage := streamOnly{filesystem.New(t.TempDir())}
+	for _, path := range []string{"/a/1.txt", "/a/2.txt", "/a/b/3.txt", "/c.txt"} {
+		storage.Put(path, strings.NewReader(path))
+	}
+
+	var paths []string
+	for object, err := range oss.Objects(context.Background(), storage, oss.ListOptions{Prefix: "/a/", MaxKeys: 1}) {
+		if err != nil {
+			t.Fatalf("No error should happen when iterate objects, but got %v", err)
+		}
+		paths = append(paths, object.Path)
+	}
+
+	if !reflect.DeepEqual(paths, []string{"/a/1.txt", "/a/2.txt", "/a/b/"}) {
+		t.Errorf("Should iterate objects and common prefixes under /a/, but got %v", paths)
+	}
+}
@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"net/url"
+
+	"github.com/qor/oss"
+)
+
+func init() {
+	oss.Register("mem", Open)
+}
+
+// Open initialize in-memory storage with URL mem://, it is registered as the mem driver
+func Open(u *url.URL) (oss.StorageInterface, error) {
+	return New(), nil
+}
@@ -0,0 +1,369 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qor/oss"
+)
+
+var (
+	_ oss.ContextStorage = (*Storage)(nil)
+	_ oss.Stater         = (*Storage)(nil)
+	_ oss.RangeGetter    = (*Storage)(nil)
+	_ oss.Lister         = (*Storage)(nil)
+	_ oss.Copier         = (*Storage)(nil)
+	_ oss.BatchDeleter   = (*Storage)(nil)
+)
+
+// Storage in-memory storage, it is safe for concurrent use, could be used in tests instead of real storages, the zero value is ready to use
+type Storage struct {
+	mutex   sync.RWMutex
+	objects map[string]*object
+}
+
+// object a stored file, its content is never modified after stored, so it could be read without lock
+type object struct {
+	content      []byte
+	etag         string
+	contentType  string
+	lastModified time.Time
+	metadata     map[string]string
+}
+
+// New initialize in-memory storage
+func New() *Storage {
+	return &Storage{objects: map[string]*object{}}
+}
+
+// key get key of path, keys are always start with /
+func key(path string) string {
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+// load get object of path
+func (storage *Storage) load(path string) (*object, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	if obj, ok := storage.objects[key(path)]; ok {
+		return obj, nil
+	}
+	return nil, oss.WrapError(oss.ErrNotExist, fmt.Errorf("memory: %v not found", path))
+}
+
+// store save object into path, objects are initialized if the storage is the zero value
+func (storage *Storage) store(path string, obj *object) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if storage.objects == nil {
+		storage.objects = map[string]*object{}
+	}
+	storage.objects[key(path)] = obj
+}
+
+// Get receive file with given path, the content is written into a temporary file
+func (storage *Storage) Get(path string) (*os.File, error) {
+	return storage.GetContext(context.Background(), path)
+}
+
+// GetContext receive file with given path
+func (storage *Storage) GetContext(ctx context.Context, path string) (*os.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	obj, err := storage.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp("", "memory*"+filepath.Ext(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Write(obj.content); err == nil {
+		_, err = file.Seek(0, 0)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetStream get file as stream
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	return storage.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream, reading from it stops once the context is done
+func (storage *Storage) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	obj, err := storage.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return oss.NewContextReadCloser(ctx, io.NopCloser(bytes.NewReader(obj.content))), nil
+}
+
+// GetRange get length bytes from offset of the file as stream, negative length reads to the end of the file
+func (storage *Storage) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return storage.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream
+func (storage *Storage) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := oss.CheckRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	obj, err := storage.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 && offset >= int64(len(obj.content)) {
+		return nil, oss.WrapError(oss.ErrInvalidRange, fmt.Errorf("offset %d is beyond the end of %s", offset, path))
+	}
+	stream := io.NopCloser(bytes.NewReader(obj.content[offset:]))
+	return oss.LimitReadCloser(oss.NewContextReadCloser(ctx, stream), length), nil
+}
+
+// Put store a reader into given path
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutContext(context.Background(), path, reader)
+}
+
+// PutContext store a reader into given path, reading from the reader stops once the context is done
+func (storage *Storage) PutContext(ctx context.Context, path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutWithMetadata(ctx, path, reader, nil)
+}
+
+// PutWithMetadata store a reader into given path with metadata, metadata could be retrieved with Stat
+func (storage *Storage) PutWithMetadata(ctx context.Context, path string, reader io.Reader, metadata map[string]string) (*oss.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		seeker.Seek(0, 0)
+	}
+
+	content, err := io.ReadAll(oss.NewContextReader(ctx, reader))
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &object{
+		content:      content,
+		contentType:  contentType(path, content),
+		lastModified: time.Now(),
+		metadata:     map[string]string{},
+	}
+	checksum := md5.Sum(content)
+	obj.etag = hex.EncodeToString(checksum[:])
+	for key, value := range metadata {
+		obj.metadata[strings.ToLower(key)] = value
+	}
+
+	storage.store(path, obj)
+
+	return storage.toObject(key(path), obj), nil
+}
+
+// contentType get content type from path's extension or content
+func contentType(path string, content []byte) string {
+	contentType, _, _ := oss.DetectContentType(path, bytes.NewReader(content))
+	return contentType
+}
+
+// Stat get file's information
+func (storage *Storage) Stat(path string) (*oss.ObjectInfo, error) {
+	return storage.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information
+func (storage *Storage) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	obj, err := storage.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified := obj.lastModified
+	info := &oss.ObjectInfo{
+		Path:         path,
+		Name:         filepath.Base(path),
+		Size:         int64(len(obj.content)),
+		ETag:         obj.etag,
+		ContentType:  obj.contentType,
+		LastModified: &lastModified,
+		StorageClass: "STANDARD",
+		Metadata:     map[string]string{},
+	}
+	for key, value := range obj.metadata {
+		info.Metadata[key] = value
+	}
+	return info, nil
+}
+
+// Delete delete file, deleting a missing file succeeds like cloud storages
+func (storage *Storage) Delete(path string) error {
+	return storage.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file
+func (storage *Storage) DeleteContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	storage.mutex.Lock()
+	delete(storage.objects, key(path))
+	storage.mutex.Unlock()
+	return nil
+}
+
+// DeleteMany delete files
+func (storage *Storage) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return storage.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete files one by one, files don't exist are deleted
+func (storage *Storage) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	return oss.DeleteEach(ctx, storage, paths)
+}
+
+// Copy copy file from "from" to "to"
+func (storage *Storage) Copy(from, to string) error {
+	return storage.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to", the copy shares content with the source as content is never modified
+func (storage *Storage) CopyContext(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	obj, err := storage.load(from)
+	if err != nil {
+		return err
+	}
+
+	copied := *obj
+	copied.lastModified = time.Now()
+
+	storage.store(to, &copied)
+	return nil
+}
+
+// Move move file from "from" to "to"
+func (storage *Storage) Move(from, to string) error {
+	return storage.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to"
+func (storage *Storage) MoveContext(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	obj, ok := storage.objects[key(from)]
+	if !ok {
+		return oss.WrapError(oss.ErrNotExist, fmt.Errorf("memory: %v not found", from))
+	}
+	delete(storage.objects, key(from))
+	storage.objects[key(to)] = obj
+	return nil
+}
+
+// List list all objects under current path
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	return storage.ListContext(context.Background(), path)
+}
+
+// ListContext list all objects under current path, objects are sorted by path
+func (storage *Storage) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	prefix := key(path)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var objects []*oss.Object
+	storage.mutex.RLock()
+	for key, obj := range storage.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, storage.toObject(key, obj))
+		}
+	}
+	storage.mutex.RUnlock()
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Path < objects[j].Path })
+	return objects, nil
+}
+
+// ListPage list a page of objects matched options
+func (storage *Storage) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	objects, err := storage.ListContext(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return oss.Paginate(objects, options), nil
+}
+
+// GetEndpoint get endpoint, in-memory storage's endpoint is /
+func (storage *Storage) GetEndpoint() string {
+	return "/"
+}
+
+// GetURL get public accessible URL, in-memory storage's URL is the path
+func (storage *Storage) GetURL(path string) (string, error) {
+	return storage.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (storage *Storage) GetURLContext(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// toObject convert a stored object to oss.Object
+func (storage *Storage) toObject(key string, obj *object) *oss.Object {
+	lastModified := obj.lastModified
+	return &oss.Object{
+		Path:             key,
+		Name:             filepath.Base(key),
+		LastModified:     &lastModified,
+		StorageInterface: storage,
+	}
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qor/oss/tests"
+)
+
+func TestAll(t *testing.T) {
+	tests.TestAll(New(), t)
+}
+
+func TestZeroValue(t *testing.T) {
+	tests.TestAll(&Storage{}, t)
+}
+
+func TestStatLastModified(t *testing.T) {
+	storage := New()
+	storage.Put("/sample.txt", strings.NewReader("sample"))
+
+	info, _ := storage.Stat("/sample.txt")
+	*info.LastModified = time.Time{}
+	if info, _ := storage.Stat("/sample.txt"); info.LastModified.IsZero() {
+		t.Errorf("Modifying last modified time of stat result should not change the stored file")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	storage := New()
+	if _, err := storage.PutWithMetadata(context.Background(), "/sample.txt", strings.NewReader("sample"), map[string]string{"Author": "qor"}); err != nil {
+		t.Fatalf("No error should happen when put file with metadata, but got %v", err)
+	}
+
+	if info, err := storage.Stat("/sample.txt"); err != nil {
+		t.Errorf("No error should happen when stat file, but got %v", err)
+	} else if info.Metadata["author"] != "qor" || info.ContentType != "text/plain; charset=utf-8" || info.LastModified == nil {
+		t.Errorf("Stat should return metadata, content type and last modified time, but got %+v", info)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	var (
+		storage   = New()
+		waitGroup sync.WaitGroup
+	)
+
+	for i := 0; i < 50; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			storage.Put("/sample.txt", strings.NewReader("sample"))
+			storage.List("/")
+			if stream, err := storage.GetStream("/sample.txt"); err == nil {
+				stream.Close()
+			}
+			storage.Copy("/sample.txt", "/copied.txt")
+			storage.Delete("/copied.txt")
+		}()
+	}
+	waitGroup.Wait()
+
+	if objects, _ := storage.List("/"); len(objects) != 1 {
+		t.Errorf("Should found 1 object, but got %v", len(objects))
+	}
+}
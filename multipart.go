@@ -0,0 +1,241 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MultipartUploader storages that could upload an object in parts, parts could be uploaded concurrently and retried separately
+type MultipartUploader interface {
+	InitiateMultipart(ctx context.Context, path string, contentType string) (*MultipartUpload, error)
+	UploadPart(ctx context.Context, upload *MultipartUpload, partNumber int, reader io.Reader, size int64) (*Part, error)
+	CompleteMultipart(ctx context.Context, upload *MultipartUpload, parts []*Part) (*Object, error)
+	AbortMultipart(ctx context.Context, upload *MultipartUpload) error
+	ListParts(ctx context.Context, upload *MultipartUpload) ([]*Part, error)
+}
+
+// MultipartUpload an initiated multipart upload
+type MultipartUpload struct {
+	Path        string `json:"path"`
+	UploadID    string `json:"upload_id"`
+	ContentType string `json:"content_type"`
+}
+
+// Part an uploaded part of multipart upload, part number starts from 1
+type Part struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+// MultipartState state of an upload started by UploadMultipart, persist it to resume the upload if it is interrupted
+type MultipartState struct {
+	Upload   *MultipartUpload `json:"upload"`
+	PartSize int64            `json:"part_size"`
+}
+
+// MultipartOptions options for UploadMultipart
+type MultipartOptions struct {
+	PartSize    int64 // default 16MB
+	Concurrency int   // parts uploaded at the same time, default 4
+	MaxRetries  int   // retries of each part, default 3
+	ContentType string
+
+	// State resume the upload if it has an upload, uploaded parts are skipped, the reader should read from the beginning of the file
+	State *MultipartState
+	// SaveState called after the upload initiated, could be used to persist the state to resume the upload later
+	SaveState func(state MultipartState) error
+}
+
+const (
+	defaultPartSize    = 16 << 20
+	defaultConcurrency = 4
+	defaultMaxRetries  = 3
+)
+
+// UploadMultipart upload reader in parts with uploader, parts are uploaded concurrently and retried when failed,
+// only parts being uploaded are buffered in memory.
+// If the upload failed, it is aborted unless it could be resumed with options.State or options.SaveState
+func UploadMultipart(ctx context.Context, uploader MultipartUploader, path string, reader io.Reader, options MultipartOptions) (*Object, error) {
+	if options.PartSize <= 0 {
+		options.PartSize = defaultPartSize
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultConcurrency
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	} else if options.MaxRetries == 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+
+	state := options.State
+	if state == nil {
+		state = &MultipartState{}
+	}
+
+	uploaded := map[int]*Part{}
+	if state.Upload != nil {
+		if state.PartSize > 0 {
+			options.PartSize = state.PartSize
+		}
+
+		parts, err := uploader.ListParts(ctx, state.Upload)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			if part.Size == options.PartSize {
+				uploaded[part.PartNumber] = part
+			}
+		}
+	} else {
+		contentType := options.ContentType
+		if contentType == "" {
+			var err error
+			if contentType, reader, err = DetectContentType(path, reader); err != nil {
+				return nil, err
+			}
+		}
+
+		upload, err := uploader.InitiateMultipart(ctx, path, contentType)
+		if err != nil {
+			return nil, err
+		}
+		state.Upload, state.PartSize = upload, options.PartSize
+
+		if options.SaveState != nil {
+			if err := options.SaveState(*state); err != nil {
+				uploader.AbortMultipart(context.Background(), upload)
+				return nil, err
+			}
+		}
+	}
+
+	var (
+		uploadCtx, cancel = context.WithCancel(ctx)
+		waitGroup         sync.WaitGroup
+		mutex             sync.Mutex
+		parts             []*Part
+		uploadErr         error
+		semaphore         = make(chan struct{}, options.Concurrency)
+	)
+	defer cancel()
+
+	fail := func(err error) {
+		mutex.Lock()
+		if uploadErr == nil {
+			uploadErr = err
+			cancel()
+		}
+		mutex.Unlock()
+	}
+
+	for partNumber := 1; ; partNumber++ {
+		if part, ok := uploaded[partNumber]; ok {
+			if err := skip(reader, part.Size); err != nil {
+				fail(err)
+				break
+			}
+			parts = append(parts, part)
+			continue
+		}
+
+		// the slot might be acquired after the context is done, as select picks a ready case randomly
+		acquired := false
+		select {
+		case semaphore <- struct{}{}:
+			acquired = true
+		case <-uploadCtx.Done():
+		}
+		if err := uploadCtx.Err(); err != nil {
+			if acquired {
+				<-semaphore
+			}
+			fail(err)
+			break
+		}
+
+		buffer := make([]byte, options.PartSize)
+		n, err := io.ReadFull(reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			<-semaphore
+			fail(err)
+			break
+		}
+
+		// an empty file is uploaded as one empty part
+		if n == 0 && partNumber > 1 {
+			<-semaphore
+			break
+		}
+
+		waitGroup.Add(1)
+		go func(partNumber int, buffer []byte) {
+			defer func() {
+				<-semaphore
+				waitGroup.Done()
+			}()
+
+			part, err := uploadPart(uploadCtx, uploader, state.Upload, partNumber, buffer, options.MaxRetries)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			mutex.Lock()
+			parts = append(parts, part)
+			mutex.Unlock()
+		}(partNumber, buffer[:n])
+
+		if n < len(buffer) {
+			break
+		}
+	}
+
+	waitGroup.Wait()
+
+	if uploadErr != nil {
+		if options.State == nil && options.SaveState == nil {
+			uploader.AbortMultipart(context.Background(), state.Upload)
+		}
+		return nil, uploadErr
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return uploader.CompleteMultipart(ctx, state.Upload, parts)
+}
+
+// uploadPart upload a part, retry with exponential backoff if failed
+func uploadPart(ctx context.Context, uploader MultipartUploader, upload *MultipartUpload, partNumber int, buffer []byte, maxRetries int) (part *Part, err error) {
+	backoff := 100 * time.Millisecond
+	for retry := 0; ; retry++ {
+		if part, err = uploader.UploadPart(ctx, upload, partNumber, bytes.NewReader(buffer), int64(len(buffer))); err == nil || retry >= maxRetries {
+			return part, err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// skip skip size bytes of reader, seek if it is seekable
+func skip(reader io.Reader, size int64) error {
+	if seeker, ok := reader.(io.Seeker); ok {
+		_, err := seeker.Seek(size, io.SeekCurrent)
+		return err
+	}
+
+	if _, err := io.CopyN(io.Discard, reader, size); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package oss_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/qor/oss"
+)
+
+type fakeUploader struct {
+	mutex     sync.Mutex
+	parts     map[int][]byte
+	failures  map[int]int // part number => times to fail
+	uploads   int
+	content   []byte
+	aborted   bool
+	completed bool
+}
+
+func (uploader *fakeUploader) InitiateMultipart(ctx context.Context, path string, contentType string) (*oss.MultipartUpload, error) {
+	return &oss.MultipartUpload{Path: path, UploadID: "upload", ContentType: contentType}, nil
+}
+
+func (uploader *fakeUploader) UploadPart(ctx context.Context, upload *oss.MultipartUpload, partNumber int, reader io.Reader, size int64) (*oss.Part, error) {
+	data, _ := ioutil.ReadAll(reader)
+
+	uploader.mutex.Lock()
+	defer uploader.mutex.Unlock()
+	uploader.uploads++
+	if uploader.failures[partNumber] > 0 {
+		uploader.failures[partNumber]--
+		return nil, errors.New("upload failed")
+	}
+	uploader.parts[partNumber] = data
+	return &oss.Part{PartNumber: partNumber, ETag: fmt.Sprint(partNumber), Size: int64(len(data))}, nil
+}
+
+func (uploader *fakeUploader) CompleteMultipart(ctx context.Context, upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	uploader.completed = true
+	for i, part := range parts {
+		if part.PartNumber != i+1 {
+			return nil, errors.New("parts should be sorted")
+		}
+		uploader.content = append(uploader.content, uploader.parts[part.PartNumber]...)
+	}
+	return &oss.Object{Path: upload.Path}, nil
+}
+
+func (uploader *fakeUploader) AbortMultipart(ctx context.Context, upload *oss.MultipartUpload) error {
+	uploader.aborted = true
+	return nil
+}
+
+func (uploader *fakeUploader) ListParts(ctx context.Context, upload *oss.MultipartUpload) (parts []*oss.Part, err error) {
+	for partNumber, data := range uploader.parts {
+		parts = append(parts, &oss.Part{PartNumber: partNumber, ETag: fmt.Sprint(partNumber), Size: int64(len(data))})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func TestUploadMultipart(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	uploader := &fakeUploader{parts: map[int][]byte{}, failures: map[int]int{3: 2}}
+
+	if _, err := oss.UploadMultipart(context.Background(), uploader, "/sample.txt", io.MultiReader(bytes.NewReader(content)), oss.MultipartOptions{PartSize: 16, Concurrency: 3}); err != nil {
+		t.Fatalf("No error should happen when upload in parts, but got %v", err)
+	}
+
+	if !bytes.Equal(uploader.content, content) {
+		t.Errorf("Uploaded content should be same as source, but got %v", string(uploader.content))
+	}
+
+	if uploader.uploads != 9 {
+		t.Errorf("7 parts should be uploaded with 2 retries, but got %v uploads", uploader.uploads)
+	}
+}
+
+func TestResumeUploadMultipart(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	uploader := &fakeUploader{parts: map[int][]byte{}, failures: map[int]int{5: 10}}
+
+	var state oss.MultipartState
+	options := oss.MultipartOptions{PartSize: 16, Concurrency: 1, MaxRetries: -1, SaveState: func(s oss.MultipartState) error {
+		state = s
+		return nil
+	}}
+
+	if _, err := oss.UploadMultipart(context.Background(), uploader, "/sample.txt", bytes.NewReader(content), options); err == nil {
+		t.Fatalf("Upload should fail")
+	}
+
+	if uploader.aborted || state.Upload == nil {
+		t.Fatalf("Upload with state should not be aborted, so it could be resumed")
+	}
+
+	uploader.failures, uploader.uploads = nil, 0
+	if _, err := oss.UploadMultipart(context.Background(), uploader, "/sample.txt", bytes.NewReader(content), oss.MultipartOptions{State: &state}); err != nil {
+		t.Fatalf("No error should happen when resume upload, but got %v", err)
+	}
+
+	if !bytes.Equal(uploader.content, content) {
+		t.Errorf("Uploaded content should be same as source, but got %v", string(uploader.content))
+	}
+
+	if uploader.uploads != 3 {
+		t.Errorf("Only parts not uploaded should be uploaded when resuming, but got %v uploads", uploader.uploads)
+	}
+}
+
+// cancelReader cancel the context after n bytes are read
+type cancelReader struct {
+	io.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (reader *cancelReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	if reader.n -= n; reader.n <= 0 {
+		reader.cancel()
+	}
+	return n, err
+}
+
+func TestCancelUploadMultipart(t *testing.T) {
+	// select picks the semaphore or the done context randomly, upload several times to cover both
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		uploader := &fakeUploader{parts: map[int][]byte{}}
+		reader := &cancelReader{Reader: bytes.NewReader(bytes.Repeat([]byte("0123456789"), 10)), n: 16, cancel: cancel}
+
+		if _, err := oss.UploadMultipart(ctx, uploader, "/sample.txt", reader, oss.MultipartOptions{PartSize: 16, Concurrency: 10}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Upload should fail with canceled context, but got %v", err)
+		}
+		if !uploader.aborted || uploader.completed {
+			t.Fatalf("Canceled upload should be aborted rather than completed, but aborted: %v, completed: %v", uploader.aborted, uploader.completed)
+		}
+	}
+}
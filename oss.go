@@ -1,6 +1,7 @@
 package oss
 
 import (
+	"context"
 	"io"
 	"os"
 	"time"
@@ -29,3 +30,22 @@ type Object struct {
 func (object Object) Get() (*os.File, error) {
 	return object.StorageInterface.Get(object.Path)
 }
+
+// Stater storages that could retrieve object's information without downloading it
+type Stater interface {
+	Stat(path string) (*ObjectInfo, error)
+	StatContext(ctx context.Context, path string) (*ObjectInfo, error)
+}
+
+// ObjectInfo object's information, like size, ETag, content type and user metadata
+type ObjectInfo struct {
+	Path         string
+	Name         string
+	Size         int64
+	ETag         string
+	ContentType  string
+	CacheControl string
+	LastModified *time.Time
+	StorageClass string
+	Metadata     map[string]string
+}
@@ -0,0 +1,263 @@
+package prefix
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/qor/oss"
+)
+
+var (
+	_ oss.ContextStorage = (*Storage)(nil)
+	_ oss.Stater         = (*Storage)(nil)
+	_ oss.RangeGetter    = (*Storage)(nil)
+	_ oss.Lister         = (*Storage)(nil)
+	_ oss.Copier         = (*Storage)(nil)
+	_ oss.URLSigner      = (*Storage)(nil)
+	_ oss.BatchDeleter   = (*Storage)(nil)
+)
+
+// Storage wrap a storage to save files under a prefix, e.g. files of path /a.txt are saved as /uploads/a.txt with prefix /uploads,
+// paths of returned objects are relative to the prefix
+type Storage struct {
+	Storage oss.StorageInterface
+	// Prefix prefix of saved files, starts with / and doesn't end with /, e.g. /uploads
+	Prefix string
+}
+
+// New wrap storage to save files under prefix
+func New(storage oss.StorageInterface, prefix string) *Storage {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix = "/" + prefix
+	}
+	return &Storage{Storage: storage, Prefix: prefix}
+}
+
+// Wrap wrap storage to save files under prefix, storage is returned as it is if prefix is blank or /
+func Wrap(storage oss.StorageInterface, prefix string) oss.StorageInterface {
+	if strings.Trim(prefix, "/") == "" {
+		return storage
+	}
+	return New(storage, prefix)
+}
+
+// ToKey convert path to the path in the wrapped storage
+func (storage *Storage) ToKey(path string) string {
+	return storage.Prefix + "/" + strings.TrimPrefix(path, "/")
+}
+
+// ToRelativePath convert path in the wrapped storage to the path relative to the prefix
+func (storage *Storage) ToRelativePath(key string) string {
+	key = "/" + strings.TrimPrefix(key, "/")
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(key, storage.Prefix), "/")
+}
+
+// toObject convert object of the wrapped storage
+func (storage *Storage) toObject(object *oss.Object) *oss.Object {
+	if object == nil {
+		return nil
+	}
+	relative := *object
+	relative.Path = storage.ToRelativePath(object.Path)
+	relative.StorageInterface = storage
+	return &relative
+}
+
+// Get receive file with given path
+func (storage *Storage) Get(path string) (*os.File, error) {
+	return storage.GetContext(context.Background(), path)
+}
+
+// GetContext receive file with given path
+func (storage *Storage) GetContext(ctx context.Context, path string) (*os.File, error) {
+	return oss.WithContext(storage.Storage).GetContext(ctx, storage.ToKey(path))
+}
+
+// GetStream get file as stream
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	return storage.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream
+func (storage *Storage) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	return oss.WithContext(storage.Storage).GetStreamContext(ctx, storage.ToKey(path))
+}
+
+// GetRange get length bytes from offset of the file as stream
+func (storage *Storage) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return storage.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream
+func (storage *Storage) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	return oss.GetRange(ctx, storage.Storage, storage.ToKey(path), offset, length)
+}
+
+// Put store a reader into given path
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutContext(context.Background(), path, reader)
+}
+
+// PutContext store a reader into given path
+func (storage *Storage) PutContext(ctx context.Context, path string, reader io.Reader) (*oss.Object, error) {
+	object, err := oss.WithContext(storage.Storage).PutContext(ctx, storage.ToKey(path), reader)
+	if err != nil {
+		return nil, err
+	}
+	return storage.toObject(object), nil
+}
+
+// Stat get file's information, the wrapped storage needs to implement oss.Stater
+func (storage *Storage) Stat(path string) (*oss.ObjectInfo, error) {
+	return storage.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information
+func (storage *Storage) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	stater, ok := storage.Storage.(oss.Stater)
+	if !ok {
+		return nil, fmt.Errorf("prefix: %T doesn't implement oss.Stater", storage.Storage)
+	}
+
+	info, err := stater.StatContext(ctx, storage.ToKey(path))
+	if err != nil {
+		return nil, err
+	}
+	relative := *info
+	relative.Path = storage.ToRelativePath(info.Path)
+	return &relative, nil
+}
+
+// Delete delete file
+func (storage *Storage) Delete(path string) error {
+	return storage.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file
+func (storage *Storage) DeleteContext(ctx context.Context, path string) error {
+	return oss.WithContext(storage.Storage).DeleteContext(ctx, storage.ToKey(path))
+}
+
+// DeleteMany delete objects of paths in batches if the wrapped storage supports it
+func (storage *Storage) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return storage.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete objects of paths in batches if the wrapped storage supports it
+func (storage *Storage) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	keys := make([]string, len(paths))
+	for i, path := range paths {
+		keys[i] = storage.ToKey(path)
+	}
+
+	// report paths as requested, keys are mapped back to the paths
+	requested := make(map[string]string, len(paths))
+	for i, key := range keys {
+		if _, ok := requested[key]; !ok {
+			requested[key] = paths[i]
+		}
+	}
+
+	result, err := oss.DeleteMany(ctx, storage.Storage, keys)
+	var relative oss.BatchResult
+	for _, key := range result.Deleted {
+		relative.Deleted = append(relative.Deleted, requested[key])
+	}
+	for _, failure := range result.Failures {
+		relative.Failures = append(relative.Failures, oss.BatchFailure{Path: requested[failure.Path], Err: failure.Err})
+	}
+	if err != nil && len(relative.Failures) > 0 {
+		err = relative.Err()
+	}
+	return relative, err
+}
+
+// List list all objects under current path
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	return storage.ListContext(context.Background(), path)
+}
+
+// ListContext list all objects under current path
+func (storage *Storage) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
+	objects, err := oss.WithContext(storage.Storage).ListContext(ctx, strings.TrimPrefix(storage.ToKey(path), "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	relative := make([]*oss.Object, 0, len(objects))
+	for _, object := range objects {
+		relative = append(relative, storage.toObject(object))
+	}
+	return relative, nil
+}
+
+// ListPage list a page of objects matched options, continuation tokens of the wrapped storage are returned as they are
+func (storage *Storage) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	options.Prefix = storage.ToKey(options.Prefix)
+	if options.StartAfter != "" {
+		options.StartAfter = storage.ToKey(options.StartAfter)
+	}
+
+	result, err := oss.List(ctx, storage.Storage, options)
+	if err != nil {
+		return nil, err
+	}
+
+	relative := &oss.ListResult{IsTruncated: result.IsTruncated, NextContinuationToken: result.NextContinuationToken}
+	for _, object := range result.Objects {
+		relative.Objects = append(relative.Objects, storage.toObject(object))
+	}
+	for _, commonPrefix := range result.CommonPrefixes {
+		relative.CommonPrefixes = append(relative.CommonPrefixes, storage.ToRelativePath(commonPrefix))
+	}
+	return relative, nil
+}
+
+// Copy copy file from "from" to "to"
+func (storage *Storage) Copy(from, to string) error {
+	return storage.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to"
+func (storage *Storage) CopyContext(ctx context.Context, from, to string) error {
+	return oss.Copy(ctx, storage.Storage, storage.ToKey(from), storage.ToKey(to))
+}
+
+// Move move file from "from" to "to"
+func (storage *Storage) Move(from, to string) error {
+	return storage.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to"
+func (storage *Storage) MoveContext(ctx context.Context, from, to string) error {
+	return oss.Move(ctx, storage.Storage, storage.ToKey(from), storage.ToKey(to))
+}
+
+// GetEndpoint get endpoint of the wrapped storage
+func (storage *Storage) GetEndpoint() string {
+	return storage.Storage.GetEndpoint()
+}
+
+// GetURL get public accessible URL
+func (storage *Storage) GetURL(path string) (string, error) {
+	return storage.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (storage *Storage) GetURLContext(ctx context.Context, path string) (string, error) {
+	return oss.WithContext(storage.Storage).GetURLContext(ctx, storage.ToKey(path))
+}
+
+// GetSignedURL generate a pre-signed URL with the wrapped storage
+func (storage *Storage) GetSignedURL(path string, options oss.URLOptions) (string, error) {
+	return storage.GetSignedURLContext(context.Background(), path, options)
+}
+
+// GetSignedURLContext generate a pre-signed URL with the wrapped storage
+func (storage *Storage) GetSignedURLContext(ctx context.Context, path string, options oss.URLOptions) (string, error) {
+	return oss.GetSignedURL(ctx, storage.Storage, storage.ToKey(path), options)
+}
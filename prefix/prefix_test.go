@@ -0,0 +1,78 @@
+package prefix
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/fault"
+	"github.com/qor/oss/memory"
+	"github.com/qor/oss/tests"
+)
+
+func TestAll(t *testing.T) {
+	tests.TestAll(New(memory.New(), "/prefix"), t)
+}
+
+func TestPrefix(t *testing.T) {
+	storage := memory.New()
+	prefixed := New(storage, "uploads/")
+
+	object, err := prefixed.Put("/a.txt", strings.NewReader("a"))
+	if err != nil || object.Path != "/a.txt" || object.StorageInterface != prefixed {
+		t.Errorf("Put object's path should be relative to the prefix, but got %+v, %v", object, err)
+	}
+	prefixed.Put("b/c.txt", strings.NewReader("c"))
+	storage.Put("/outside.txt", strings.NewReader("outside"))
+
+	if objects, _ := storage.List(""); len(objects) != 3 || objects[0].Path != "/outside.txt" || objects[1].Path != "/uploads/a.txt" || objects[2].Path != "/uploads/b/c.txt" {
+		t.Errorf("Files should be saved under the prefix, but got %v", objects)
+	}
+
+	if objects, _ := prefixed.List(""); len(objects) != 2 || objects[0].Path != "/a.txt" || objects[1].Path != "/b/c.txt" {
+		t.Errorf("Only files under the prefix should be listed, but got %v", objects)
+	}
+
+	result, err := oss.List(context.Background(), prefixed, oss.ListOptions{})
+	if err != nil || len(result.Objects) != 1 || result.Objects[0].Path != "/a.txt" || !reflect.DeepEqual(result.CommonPrefixes, []string{"/b/"}) {
+		t.Errorf("Listed page should be relative to the prefix, but got %+v, %v", result, err)
+	}
+
+	if info, err := prefixed.Stat("/b/c.txt"); err != nil || info.Path != "/b/c.txt" || info.Size != 1 {
+		t.Errorf("Stat path should be relative to the prefix, but got %+v, %v", info, err)
+	}
+	if url, _ := prefixed.GetURL("/a.txt"); url != "/uploads/a.txt" {
+		t.Errorf("URL should contain the prefix, but got %v", url)
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	storage := fault.New(memory.New()).Inject(fault.Rule{Op: fault.OpDelete, Path: "/uploads/denied/*", Err: oss.ErrPermission})
+	prefixed := New(storage, "/uploads")
+	for _, path := range []string{"/a.txt", "/denied/b.txt"} {
+		prefixed.Put(path, strings.NewReader(path))
+	}
+
+	result, err := prefixed.DeleteMany([]string{"a.txt", "/denied/b.txt"})
+	if !errors.Is(err, oss.ErrPermission) || !reflect.DeepEqual(result.Deleted, []string{"a.txt"}) {
+		t.Errorf("Deleted paths should be reported as requested, but got %+v, %v", result, err)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Path != "/denied/b.txt" {
+		t.Errorf("Failed paths should be reported as requested, but got %+v", result.Failures)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	storage := memory.New()
+	for _, prefix := range []string{"", "/"} {
+		if wrapped := Wrap(storage, prefix); wrapped != oss.StorageInterface(storage) {
+			t.Errorf("Storage should not be wrapped with blank prefix %q, but got %#v", prefix, wrapped)
+		}
+	}
+	if wrapped, ok := Wrap(storage, "/uploads/").(*Storage); !ok || wrapped.Prefix != "/uploads" {
+		t.Errorf("Storage should be wrapped with prefix, but got %#v", wrapped)
+	}
+}
@@ -0,0 +1,154 @@
+package qiniu
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/qiniu/api.v7/v7/storage"
+	"github.com/qor/oss"
+)
+
+var _ oss.MultipartUploader = (*Client)(nil)
+
+// InitiateMultipart initiate a multipart upload with Qiniu's resumable upload API (v2)
+func (client Client) InitiateMultipart(ctx context.Context, path string, contentType string) (*oss.MultipartUpload, error) {
+	key := storageKey(path)
+	upHost, err := client.upHost()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := storage.InitPartsRet{}
+	if err := client.resumeUploader().InitParts(ctx, client.uploadToken(key), upHost, client.Config.Bucket, key, true, &ret); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.MultipartUpload{Path: path, UploadID: ret.UploadID, ContentType: contentType}, nil
+}
+
+// UploadPart upload a part
+func (client Client) UploadPart(ctx context.Context, upload *oss.MultipartUpload, partNumber int, reader io.Reader, size int64) (*oss.Part, error) {
+	key := storageKey(upload.Path)
+	upHost, err := client.upHost()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := storage.UploadPartsRet{}
+	if err := client.resumeUploader().UploadParts(ctx, client.uploadToken(key), upHost, client.Config.Bucket, key, true, upload.UploadID, int64(partNumber), "", &ret, reader, int(size)); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Part{PartNumber: partNumber, ETag: ret.Etag, Size: size}, nil
+}
+
+// CompleteMultipart complete a multipart upload with uploaded parts
+func (client Client) CompleteMultipart(ctx context.Context, upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	type completePart struct {
+		Etag       string `json:"etag"`
+		PartNumber int    `json:"partNumber"`
+	}
+
+	body := struct {
+		Parts    []completePart `json:"parts"`
+		MimeType string         `json:"mimeType,omitempty"`
+	}{MimeType: upload.ContentType}
+	for _, part := range parts {
+		body.Parts = append(body.Parts, completePart{Etag: part.ETag, PartNumber: part.PartNumber})
+	}
+
+	uploadURL, token, err := client.uploadURL(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := storage.PutRet{}
+	if err := client.bucketManager.Client.CallWithJson(ctx, &ret, "POST", uploadURL, uploadHeaders(token), &body); err != nil {
+		return nil, wrapError(err)
+	}
+
+	now := time.Now()
+	return &oss.Object{
+		Path:             upload.Path,
+		Name:             filepath.Base(upload.Path),
+		LastModified:     &now,
+		StorageInterface: client,
+	}, nil
+}
+
+// AbortMultipart abort a multipart upload, uploaded parts will be removed
+func (client Client) AbortMultipart(ctx context.Context, upload *oss.MultipartUpload) error {
+	uploadURL, token, err := client.uploadURL(upload)
+	if err != nil {
+		return err
+	}
+
+	return wrapError(client.bucketManager.Client.Call(ctx, nil, "DELETE", uploadURL, uploadHeaders(token)))
+}
+
+// ListParts list uploaded parts of a multipart upload
+func (client Client) ListParts(ctx context.Context, upload *oss.MultipartUpload) ([]*oss.Part, error) {
+	uploadURL, token, err := client.uploadURL(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		parts  []*oss.Part
+		marker int
+	)
+
+	for {
+		ret := struct {
+			PartNumberMarker int `json:"partNumberMarker"`
+			Parts            []struct {
+				Size       int64  `json:"size"`
+				Etag       string `json:"etag"`
+				PartNumber int    `json:"partNumber"`
+			} `json:"parts"`
+		}{}
+
+		reqURL := fmt.Sprintf("%s?max-parts=1000&part-number-marker=%d", uploadURL, marker)
+		if err := client.bucketManager.Client.Call(ctx, &ret, "GET", reqURL, uploadHeaders(token)); err != nil {
+			return nil, wrapError(err)
+		}
+
+		for _, part := range ret.Parts {
+			parts = append(parts, &oss.Part{PartNumber: part.PartNumber, ETag: part.Etag, Size: part.Size})
+		}
+
+		if ret.PartNumberMarker == 0 || ret.PartNumberMarker == marker {
+			return parts, nil
+		}
+		marker = ret.PartNumberMarker
+	}
+}
+
+func (client Client) resumeUploader() *storage.ResumeUploaderV2 {
+	return storage.NewResumeUploaderV2Ex(&client.storageCfg, client.bucketManager.Client)
+}
+
+func (client Client) upHost() (string, error) {
+	return client.resumeUploader().UpHost(client.mac.AccessKey, client.Config.Bucket)
+}
+
+// uploadURL get URL and upload token of a multipart upload
+func (client Client) uploadURL(upload *oss.MultipartUpload) (string, string, error) {
+	key := storageKey(upload.Path)
+	upHost, err := client.upHost()
+	if err != nil {
+		return "", "", err
+	}
+
+	uploadURL := fmt.Sprintf("%s/buckets/%s/objects/%s/uploads/%s", upHost, client.Config.Bucket, base64.URLEncoding.EncodeToString([]byte(key)), upload.UploadID)
+	return uploadURL, client.uploadToken(key), nil
+}
+
+func uploadHeaders(token string) http.Header {
+	return http.Header{"Authorization": []string{"UpToken " + token}}
+}
@@ -1,8 +1,8 @@
 package qiniu
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,24 +10,38 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/qiniu/api.v7/v7/auth"
 	"github.com/qiniu/api.v7/v7/auth/qbox"
+	qiniuclient "github.com/qiniu/api.v7/v7/client"
 	"github.com/qiniu/api.v7/v7/storage"
 	"github.com/qor/oss"
 )
 
+var (
+	_ oss.ContextStorage = (*Client)(nil)
+	_ oss.Stater         = (*Client)(nil)
+	_ oss.RangeGetter    = (*Client)(nil)
+	_ oss.Lister         = (*Client)(nil)
+	_ oss.Copier         = (*Client)(nil)
+	_ oss.URLSigner      = (*Client)(nil)
+	_ oss.BatchDeleter   = (*Client)(nil)
+)
+
 // Client Qiniu storage
 type Client struct {
 	Config        *Config
 	mac           *qbox.Mac
 	storageCfg    storage.Config
 	bucketManager *storage.BucketManager
-	putPolicy     *storage.PutPolicy
+	// putPolicy policy set with SetPutPolicy, it is shared by copies of the client and could be set while uploading
+	putPolicy *atomic.Pointer[storage.PutPolicy]
 }
 
 // Config Qiniu client config
@@ -40,8 +54,16 @@ type Config struct {
 	UseHTTPS      bool
 	UseCdnDomains bool
 	PrivateURL    bool
+	// Zone hosts of upload, rs and rsf APIs, overrides Region, could be used to connect to private deployments or fake servers
+	Zone *storage.Zone
+	// PutPolicy upload policy of files, like expiry of upload tokens, callback, insertOnly, fsizeLimit, mimeLimit and persistent ops,
+	// its Scope is set to the uploaded file's key automatically
+	PutPolicy *storage.PutPolicy
 }
 
+// storageClasses Qiniu's file types, 0 is standard, 1 is infrequent access, 2 is archive, 3 is deep archive
+var storageClasses = []string{"STANDARD", "LINE", "ARCHIVE", "DEEP_ARCHIVE"}
+
 var zonedata = map[string]*storage.Zone{
 	"huadong": &storage.ZoneHuadong,
 	"huabei":  &storage.ZoneHuabei,
@@ -49,39 +71,102 @@ var zonedata = map[string]*storage.Zone{
 	"beimei":  &storage.ZoneBeimei,
 }
 
+// New initialize Qiniu storage, it panics if Region or Endpoint is invalid, use NewWithError to validate config and get the error
 func New(config *Config) *Client {
+	client, err := newClient(config)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
 
-	client := &Client{Config: config, storageCfg: storage.Config{}}
+// NewWithError validate config and initialize Qiniu storage, returns *oss.ConfigError if a field of config is invalid
+func NewWithError(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return newClient(config)
+}
 
-	client.mac = qbox.NewMac(config.AccessID, config.AccessKey)
+// Validate check fields of config, returns *oss.ConfigError of the first invalid field
+func (config Config) Validate() error {
+	switch {
+	case config.AccessID == "":
+		return oss.NewConfigError("qiniu", "AccessID", "is required")
+	case config.AccessKey == "":
+		return oss.NewConfigError("qiniu", "AccessKey", "is required")
+	case config.Bucket == "":
+		return oss.NewConfigError("qiniu", "Bucket", "is required")
+	}
+	return validateZone(config)
+}
 
-	if z, ok := zonedata[strings.ToLower(config.Region)]; ok {
-		client.storageCfg.Zone = z
-	} else {
-		panic(fmt.Sprintf("Zone %s is invalid, only support huadong, huabei, huanan, beimei.", config.Region))
+// validateZone check Zone, Region and Endpoint, which are required to initialize the client
+func validateZone(config Config) error {
+	if _, ok := zonedata[strings.ToLower(config.Region)]; !ok && config.Zone == nil {
+		return oss.NewConfigError("qiniu", "Region", fmt.Sprintf("%q is invalid, only support huadong, huabei, huanan, beimei", config.Region))
 	}
 	if len(config.Endpoint) == 0 {
-		panic("endpoint must be provided.")
+		return oss.NewConfigError("qiniu", "Endpoint", "is required")
+	}
+	return nil
+}
+
+// newClient initialize Qiniu storage, returns error if Region or Endpoint is invalid
+func newClient(config *Config) (*Client, error) {
+	if err := validateZone(*config); err != nil {
+		return nil, err
+	}
+
+	client := &Client{Config: config, storageCfg: storage.Config{}, putPolicy: &atomic.Pointer[storage.PutPolicy]{}}
+
+	client.mac = qbox.NewMac(config.AccessID, config.AccessKey)
+
+	if config.Zone != nil {
+		client.storageCfg.Zone = config.Zone
+	} else {
+		client.storageCfg.Zone = zonedata[strings.ToLower(config.Region)]
 	}
 	client.storageCfg.UseHTTPS = config.UseHTTPS
 	client.storageCfg.UseCdnDomains = config.UseCdnDomains
 	client.bucketManager = storage.NewBucketManager(client.mac, &client.storageCfg)
 
-	return client
+	return client, nil
 }
 
+// SetPutPolicy set upload policy of files, it overrides Config.PutPolicy and is shared by copies of the client,
+// it is safe to call while uploading files. Setting nil uses Config.PutPolicy again
+//
+// Deprecated: set Config.PutPolicy instead
 func (client Client) SetPutPolicy(putPolicy *storage.PutPolicy) {
-	client.putPolicy = putPolicy
+	client.putPolicy.Store(putPolicy)
+}
+
+// defaultPutPolicy get policy set with SetPutPolicy, or Config.PutPolicy if it isn't set
+func (client Client) defaultPutPolicy() *storage.PutPolicy {
+	if client.putPolicy != nil {
+		if putPolicy := client.putPolicy.Load(); putPolicy != nil {
+			return putPolicy
+		}
+	}
+	return client.Config.PutPolicy
 }
 
 // Get receive file with given path
 func (client Client) Get(path string) (file *os.File, err error) {
-	readCloser, err := client.GetStream(path)
+	return client.GetContext(context.Background(), path)
+}
 
-	if file, err = ioutil.TempFile("/tmp", "qiniu"); err == nil {
-		defer readCloser.Close()
-		_, err = io.Copy(file, readCloser)
-		file.Seek(0, 0)
+// GetContext receive file with given path
+func (client Client) GetContext(ctx context.Context, path string) (file *os.File, err error) {
+	readCloser, err := client.GetStreamContext(ctx, path)
+
+	if err == nil {
+		if file, err = ioutil.TempFile("/tmp", "qiniu"); err == nil {
+			defer readCloser.Close()
+			_, err = io.Copy(file, readCloser)
+			file.Seek(0, 0)
+		}
 	}
 
 	return file, err
@@ -89,101 +174,347 @@ func (client Client) Get(path string) (file *os.File, err error) {
 
 // GetStream get file as stream
 func (client Client) GetStream(path string) (io.ReadCloser, error) {
-	purl, err := client.GetURL(path)
+	return client.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream, it is downloaded with a signed URL, so files in private buckets could be read even if PrivateURL is false
+func (client Client) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	res, err := client.download(ctx, path, "")
 	if err != nil {
 		return nil, err
 	}
 
-	var res *http.Response
-	res, err = http.Get(purl)
-	if err == nil && res.StatusCode != http.StatusOK {
-		err = fmt.Errorf("file %s not found", path)
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, responseError(res)
 	}
 
-	return res.Body, err
+	return res.Body, nil
 }
 
-// Put store a reader into given path
-func (client Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err error) {
-	if seeker, ok := reader.(io.ReadSeeker); ok {
-		seeker.Seek(0, 0)
+// download send a download request of the file with a signed URL, rangeHeader is sent as Range header if it isn't blank
+func (client Client) download(ctx context.Context, path string, rangeHeader string) (*http.Response, error) {
+	downloadURL, err := client.GetSignedURLContext(ctx, path, oss.URLOptions{})
+	if err != nil {
+		return nil, err
 	}
 
-	urlPath = storageKey(urlPath)
-	var buffer []byte
-	buffer, err = ioutil.ReadAll(reader)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
 	if err != nil {
-		return
+		return nil, err
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
 	}
 
-	fileType := mime.TypeByExtension(path.Ext(urlPath))
-	if fileType == "" {
-		fileType = http.DetectContentType(buffer)
+	return client.bucketManager.Client.Do(ctx, req)
+}
+
+// GetRange get length bytes from offset of the file as stream, negative length reads to the end of the file
+func (client Client) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return client.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream with Range header,
+// if the range is ignored by the server, bytes before offset are discarded
+func (client Client) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.CheckRange(offset, length); err != nil {
+		return nil, err
 	}
 
-	putPolicy := storage.PutPolicy{
-		Scope: fmt.Sprintf("%s:%s", client.Config.Bucket, urlPath),
+	res, err := client.download(ctx, path, oss.HTTPRange(offset, length))
+	if err != nil {
+		return nil, err
 	}
 
-	if client.putPolicy != nil {
-		putPolicy = *client.putPolicy
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		return oss.LimitReadCloser(res.Body, length), nil
+	case http.StatusOK:
+		return oss.SkipReadCloser(res.Body, path, offset, length)
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset == 0 {
+			res.Body.Close()
+			return http.NoBody, nil // empty file
+		}
+	}
+
+	defer res.Body.Close()
+	return nil, responseError(res)
+}
+
+// Put store a reader into given path
+func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutContext(context.Background(), urlPath, reader)
+}
+
+// PutContext store a reader into given path with Config.PutPolicy, readers with known size are streamed with form upload,
+// others are uploaded in parts with resumable upload, so the reader is never read into memory as a whole
+func (client Client) PutContext(ctx context.Context, urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutWithPolicy(ctx, urlPath, reader, client.defaultPutPolicy())
+}
+
+// PutWithPolicy store a reader into given path with the upload policy, its Scope is set to the file's key,
+// Config.PutPolicy is used if putPolicy is nil
+func (client Client) PutWithPolicy(ctx context.Context, urlPath string, reader io.Reader, putPolicy *storage.PutPolicy) (r *oss.Object, err error) {
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		seeker.Seek(0, 0)
 	}
 
-	upToken := putPolicy.UploadToken(client.mac)
+	key := storageKey(urlPath)
+
+	var fileType string
+	if fileType, reader, err = oss.DetectContentType(key, reader); err != nil {
+		return
+	}
 
-	formUploader := storage.NewFormUploader(&client.storageCfg)
+	upToken := client.uploadTokenWithPolicy(key, putPolicy)
 	ret := storage.PutRet{}
-	dataLen := int64(len(buffer))
 
-	putExtra := storage.PutExtra{
-		Params: map[string]string{},
+	if size, ok := oss.ReaderSize(reader); ok {
+		formUploader := storage.NewFormUploader(&client.storageCfg)
+		putExtra := storage.PutExtra{
+			Params:   map[string]string{},
+			MimeType: fileType,
+		}
+		err = formUploader.Put(ctx, &ret, upToken, key, reader, size, &putExtra)
+	} else {
+		resumeUploader := storage.NewResumeUploaderV2(&client.storageCfg)
+		err = resumeUploader.PutWithoutSize(ctx, &ret, upToken, key, reader, &storage.RputV2Extra{MimeType: fileType})
 	}
-	err = formUploader.Put(context.Background(), &ret, upToken, urlPath, bytes.NewReader(buffer), dataLen, &putExtra)
+
 	if err != nil {
+		err = wrapError(err)
 		return
 	}
 
 	now := time.Now()
 	return &oss.Object{
-		Path:             ret.Key,
+		Path:             urlPath,
 		Name:             filepath.Base(urlPath),
 		LastModified:     &now,
 		StorageInterface: client,
 	}, err
 }
 
+// uploadToken generate upload token for key with Config.PutPolicy
+func (client Client) uploadToken(key string) string {
+	return client.uploadTokenWithPolicy(key, client.defaultPutPolicy())
+}
+
+// uploadTokenWithPolicy generate upload token for key with a copy of the policy, the scope is bound to the key,
+// so existing file could be overwritten unless the policy is insert only
+func (client Client) uploadTokenWithPolicy(key string, putPolicy *storage.PutPolicy) string {
+	if putPolicy == nil {
+		putPolicy = client.defaultPutPolicy()
+	}
+
+	policy := storage.PutPolicy{}
+	if putPolicy != nil {
+		policy = *putPolicy
+	}
+
+	policy.Scope = fmt.Sprintf("%s:%s", client.Config.Bucket, key)
+	policy.IsPrefixalScope = 0
+	return policy.UploadToken(client.mac)
+}
+
+// Stat get file's information, Qiniu's hash is used as ETag
+func (client Client) Stat(path string) (*oss.ObjectInfo, error) {
+	return client.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information
+func (client Client) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	reqHost, err := client.bucketManager.RsReqHost(client.Config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileInfo storage.FileInfo
+	if err := client.call(ctx, &fileInfo, reqHost+storage.URIStat(client.Config.Bucket, storageKey(path))); err != nil {
+		return nil, err
+	}
+
+	// PutTime is in units of 100 nanoseconds
+	putTime := time.Unix(0, fileInfo.PutTime*100)
+	info := &oss.ObjectInfo{
+		Path:         path,
+		Name:         filepath.Base(path),
+		Size:         fileInfo.Fsize,
+		ETag:         fileInfo.Hash,
+		ContentType:  fileInfo.MimeType,
+		LastModified: &putTime,
+	}
+	if fileInfo.Type >= 0 && fileInfo.Type < len(storageClasses) {
+		info.StorageClass = storageClasses[fileInfo.Type]
+	}
+
+	return info, nil
+}
+
 // Delete delete file
 func (client Client) Delete(path string) error {
-	return client.bucketManager.Delete(client.Config.Bucket, storageKey(path))
+	return client.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file
+func (client Client) DeleteContext(ctx context.Context, path string) error {
+	reqHost, err := client.bucketManager.RsReqHost(client.Config.Bucket)
+	if err != nil {
+		return err
+	}
+
+	return client.call(ctx, nil, reqHost+storage.URIDelete(client.Config.Bucket, storageKey(path)))
+}
+
+// DeleteMany delete files in batches
+func (client Client) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return client.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete files in batches of 1000 operations with the batch API concurrently, files don't exist are deleted
+func (client Client) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	return oss.DeleteInBatches(ctx, paths, oss.BatchOptions{}, func(ctx context.Context, batch []string) (oss.BatchResult, error) {
+		reqHost, err := client.bucketManager.RsReqHost(client.Config.Bucket)
+		if err != nil {
+			return oss.BatchResult{}, err
+		}
+
+		var operations []string
+		for _, path := range batch {
+			operations = append(operations, storage.URIDelete(client.Config.Bucket, storageKey(path)))
+		}
+
+		var rets []storage.BatchOpRet
+		params := map[string][]string{"op": operations}
+		if err := client.bucketManager.Client.CredentialedCallWithForm(ctx, client.mac, auth.TokenQiniu, &rets, "POST", reqHost+"/batch", nil, params); err != nil {
+			return oss.BatchResult{}, wrapError(err)
+		}
+
+		var result oss.BatchResult
+		for i, ret := range rets[:min(len(rets), len(batch))] {
+			switch ret.Code {
+			case http.StatusOK, 612:
+				result.Deleted = append(result.Deleted, batch[i])
+			default:
+				err := wrapError(&qiniuclient.ErrorInfo{Code: ret.Code, Err: ret.Data.Error})
+				result.Failures = append(result.Failures, oss.BatchFailure{Path: batch[i], Err: err})
+			}
+		}
+		return result, nil
+	})
+}
+
+// Copy copy file from "from" to "to", existing file is overwritten
+func (client Client) Copy(from, to string) error {
+	return client.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to" on server side
+func (client Client) CopyContext(ctx context.Context, from, to string) error {
+	reqHost, err := client.bucketManager.RsReqHost(client.Config.Bucket)
+	if err != nil {
+		return err
+	}
+
+	return client.call(ctx, nil, reqHost+storage.URICopy(client.Config.Bucket, storageKey(from), client.Config.Bucket, storageKey(to), true))
+}
+
+// Move move file from "from" to "to", existing file is overwritten
+func (client Client) Move(from, to string) error {
+	return client.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to" on server side
+func (client Client) MoveContext(ctx context.Context, from, to string) error {
+	if storageKey(from) == storageKey(to) {
+		return nil
+	}
+
+	reqHost, err := client.bucketManager.RsReqHost(client.Config.Bucket)
+	if err != nil {
+		return err
+	}
+
+	return client.call(ctx, nil, reqHost+storage.URIMove(client.Config.Bucket, storageKey(from), client.Config.Bucket, storageKey(to), true))
 }
 
 // List list all objects under current path
-func (client Client) List(path string) (objects []*oss.Object, err error) {
-	var prefix = storageKey(path)
-	var listItems []storage.ListItem
-	listItems, _, _, _, err = client.bucketManager.ListFiles(
-		client.Config.Bucket,
-		prefix,
-		"",
-		"",
-		100,
+func (client Client) List(path string) ([]*oss.Object, error) {
+	return client.ListContext(context.Background(), path)
+}
+
+// ListContext list all objects under current path, all pages are listed by marker
+func (client Client) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
+	var (
+		objects []*oss.Object
+		options = oss.ListOptions{Recursive: true}
 	)
 
+	if path != "" {
+		options.Prefix = strings.Trim(storageKey(path), "/") + "/"
+	}
+
+	for {
+		result, err := client.ListPage(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, result.Objects...)
+		if !result.IsTruncated {
+			return objects, nil
+		}
+		options.ContinuationToken = result.NextContinuationToken
+	}
+}
+
+// ListPage list a page of objects matched options, Qiniu doesn't support start after, so objects before it are filtered out,
+// which may return pages with less objects
+func (client Client) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	reqHost, err := client.bucketManager.RsfReqHost(client.Config.Bucket)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	for _, content := range listItems {
-		t := time.Unix(content.PutTime, 0)
-		objects = append(objects, &oss.Object{
-			Path:             "/" + storageKey(content.Key),
+	query := url.Values{}
+	query.Set("bucket", client.Config.Bucket)
+	query.Set("prefix", storageKey(options.Prefix))
+	query.Set("limit", strconv.Itoa(options.GetMaxKeys()))
+	query.Set("delimiter", options.GetDelimiter())
+	query.Set("marker", options.ContinuationToken)
+
+	var ret struct {
+		Marker         string             `json:"marker"`
+		CommonPrefixes []string           `json:"commonPrefixes"`
+		Items          []storage.ListItem `json:"items"`
+	}
+	if err = client.call(ctx, &ret, reqHost+"/list?"+query.Encode()); err != nil {
+		return nil, err
+	}
+
+	startAfter := storageKey(options.StartAfter)
+	result := &oss.ListResult{IsTruncated: ret.Marker != "", NextContinuationToken: ret.Marker}
+	for _, content := range ret.Items {
+		if content.Key <= startAfter {
+			continue
+		}
+
+		t := time.Unix(0, content.PutTime*100)
+		result.Objects = append(result.Objects, &oss.Object{
+			Path:             "/" + content.Key,
 			Name:             filepath.Base(content.Key),
 			LastModified:     &t,
 			StorageInterface: client,
 		})
 	}
-
-	return
+	for _, commonPrefix := range ret.CommonPrefixes {
+		if commonPrefix > startAfter {
+			result.CommonPrefixes = append(result.CommonPrefixes, "/"+commonPrefix)
+		}
+	}
+	return result, nil
 }
 
 // GetEndpoint get endpoint, FileSystem's endpoint is /
@@ -202,20 +533,104 @@ func storageKey(urlPath string) string {
 	return strings.TrimPrefix(urlPath, "/")
 }
 
+// call send a signed request to Qiniu's resource management API, like rs and rsf
+func (client Client) call(ctx context.Context, ret interface{}, reqURL string) error {
+	return wrapError(client.bucketManager.Client.CredentialedCall(ctx, client.mac, auth.TokenQiniu, ret, "POST", reqURL, nil))
+}
+
+// responseError build error from a failed download response, it is wrapped with oss errors by the status code
+func responseError(res *http.Response) error {
+	err := qiniuclient.ResponseError(res)
+
+	var errorInfo *qiniuclient.ErrorInfo
+	if errors.As(err, &errorInfo) && errorInfo.Err == "" {
+		errorInfo.Err = res.Status
+	}
+	return wrapError(err)
+}
+
+// wrapError wrap Qiniu errors with oss errors, Qiniu uses 612 for missing file and 614 for existing file
+func wrapError(err error) error {
+	var errorInfo *qiniuclient.ErrorInfo
+	if errors.As(err, &errorInfo) {
+		switch errorInfo.Code {
+		case 612, 631:
+			return oss.WrapError(oss.ErrNotExist, err)
+		case 614:
+			return oss.WrapError(oss.ErrConflict, err)
+		}
+		return oss.WrapHTTPError(errorInfo.Code, err)
+	}
+	return err
+}
+
 // GetURL get public accessible URL
 func (client Client) GetURL(path string) (url string, err error) {
+	return client.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (client Client) GetURLContext(ctx context.Context, path string) (url string, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	if len(path) == 0 {
 		return
 	}
 	key := storageKey(path)
 
 	if client.Config.PrivateURL {
-		deadline := time.Now().Add(time.Second * 3600).Unix()
-		url = storage.MakePrivateURL(client.mac, client.Config.Endpoint, key, deadline)
-		return
+		return client.GetSignedURLContext(ctx, path, oss.URLOptions{})
 	}
 
 	url = storage.MakePublicURL(client.GetEndpoint(), key)
 
 	return
 }
+
+// GetSignedURL generate a private download URL of the file
+func (client Client) GetSignedURL(path string, options oss.URLOptions) (string, error) {
+	return client.GetSignedURLContext(context.Background(), path, options)
+}
+
+// GetSignedURLContext generate a private download URL of the file, only GET and HEAD are supported as Qiniu uploads files with upload tokens.
+// Content disposition attachment is supported by downloading with attname, the filename or the file's name if it is blank, inline is
+// the default, response content type is not supported
+func (client Client) GetSignedURLContext(ctx context.Context, path string, options oss.URLOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if method := options.GetMethod(); method != http.MethodGet && method != http.MethodHead {
+		return "", fmt.Errorf("qiniu: signed URL doesn't support method %v", options.Method)
+	}
+	if options.ResponseContentType != "" {
+		return "", errors.New("qiniu: signed URL doesn't support response content type")
+	}
+
+	urlToSign := storage.MakePublicURL(client.Config.Endpoint, storageKey(path))
+	query := url.Values{}
+	if options.ResponseContentDisposition != "" {
+		disposition, params, err := mime.ParseMediaType(options.ResponseContentDisposition)
+		if err != nil {
+			return "", err
+		}
+		switch disposition {
+		case "attachment":
+			filename := params["filename"]
+			if filename == "" {
+				filename = filepath.Base(storageKey(path))
+			}
+			query.Set("attname", filename)
+		case "inline":
+			// files are displayed inline without attname
+		default:
+			return "", fmt.Errorf("qiniu: signed URL doesn't support content disposition %v", disposition)
+		}
+	}
+	query.Set("e", strconv.FormatInt(time.Now().Add(options.GetExpires()).Unix(), 10))
+	urlToSign += "?" + query.Encode()
+
+	return urlToSign + "&token=" + client.mac.Sign([]byte(urlToSign)), nil
+}
@@ -0,0 +1,89 @@
+package oss
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RangeGetter storages that could read part of an object without downloading all of it
+type RangeGetter interface {
+	// GetRange read length bytes from offset of the object, negative length reads to the end of the object
+	GetRange(path string, offset, length int64) (io.ReadCloser, error)
+	GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// GetRange read length bytes from offset of the object, negative length reads to the end of the object.
+// Storages implemented RangeGetter read the range directly, others read the stream from the beginning and discard bytes before offset
+func GetRange(ctx context.Context, storage StorageInterface, path string, offset, length int64) (io.ReadCloser, error) {
+	if getter, ok := storage.(RangeGetter); ok {
+		return getter.GetRangeContext(ctx, path, offset, length)
+	}
+
+	if err := CheckRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	stream, err := WithContext(storage).GetStreamContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return SkipReadCloser(stream, path, offset, length)
+}
+
+// SkipReadCloser read length bytes from offset of the whole stream of path by discarding bytes before offset, it closes the stream on errors.
+// Used by storages if servers respond to Range requests with the whole object
+func SkipReadCloser(stream io.ReadCloser, path string, offset, length int64) (io.ReadCloser, error) {
+	// same as HTTP ranges, offset should be less than the file size unless it is 0
+	reader := bufio.NewReader(stream)
+	_, err := reader.Discard(int(offset))
+	if err == nil && offset > 0 {
+		_, err = reader.Peek(1)
+	}
+	if err != nil {
+		stream.Close()
+		if err == io.EOF {
+			err = WrapError(ErrInvalidRange, fmt.Errorf("offset %d is beyond the end of %s", offset, path))
+		}
+		return nil, err
+	}
+	return LimitReadCloser(limitReadCloser{reader, stream}, length), nil
+}
+
+// CheckRange check offset and length of a range, offset should not be negative
+func CheckRange(offset, length int64) error {
+	if offset < 0 {
+		return WrapError(ErrInvalidRange, fmt.Errorf("negative offset %d", offset))
+	}
+	return nil
+}
+
+// HTTPRange format offset and length as the value of HTTP Range header, negative length means to the end.
+// Zero length requests one byte as HTTP can't express an empty range, limit the response with LimitReadCloser
+func HTTPRange(offset, length int64) string {
+	if length < 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	} else if length == 0 {
+		length = 1
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// LimitReadCloser limit stream to length bytes, closing it closes the stream, negative length returns the stream as it is
+func LimitReadCloser(stream io.ReadCloser, length int64) io.ReadCloser {
+	if length < 0 {
+		return stream
+	}
+	if length == 0 {
+		stream.Close()
+		return http.NoBody
+	}
+	return limitReadCloser{io.LimitReader(stream, length), stream}
+}
+
+type limitReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,49 @@
+package oss_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/filesystem"
+)
+
+// streamOnly hide optional capabilities of the storage
+type streamOnly struct {
+	oss.StorageInterface
+}
+
+func TestGetRange(t *testing.T) {
+	storage := streamOnly{filesystem.New(t.TempDir())}
+	if _, err := storage.Put("/sample.txt", strings.NewReader("")); err != nil {
+		t.Fatalf("No error should happen when save file, but got %v", err)
+	}
+
+	if stream, err := oss.GetRange(context.Background(), storage, "/sample.txt", 0, 10); err != nil {
+		t.Errorf("No error should happen when get range of empty file, but got %v", err)
+	} else if buffer, _ := ioutil.ReadAll(stream); len(buffer) != 0 {
+		t.Errorf("Range of empty file should be empty, but got %q", buffer)
+	}
+
+	storage.Put("/sample.txt", strings.NewReader("0123456789"))
+
+	for _, r := range []struct {
+		offset, length int64
+		expected       string
+	}{{0, 3, "012"}, {3, -1, "3456789"}, {8, 10, "89"}, {9, 0, ""}} {
+		if stream, err := oss.GetRange(context.Background(), storage, "/sample.txt", r.offset, r.length); err != nil {
+			t.Errorf("No error should happen when get range %v, but got %v", r, err)
+		} else if buffer, _ := ioutil.ReadAll(stream); string(buffer) != r.expected {
+			t.Errorf("Range %v should be %q, but got %q", r, r.expected, buffer)
+		}
+	}
+
+	for _, offset := range []int64{-1, 10, 20} {
+		if _, err := oss.GetRange(context.Background(), storage, "/sample.txt", offset, 1); !errors.Is(err, oss.ErrInvalidRange) {
+			t.Errorf("Range from %v should fail with oss.ErrInvalidRange, but got %v", offset, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package oss
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+)
+
+// sniffLen bytes used to detect content type, same as http.DetectContentType
+const sniffLen = 512
+
+// DetectContentType get content type from path's extension, if it is unknown, sniff it from the first 512 bytes of reader.
+// The returned reader should be used instead of reader, it reads from the position reader was at
+func DetectContentType(urlPath string, reader io.Reader) (string, io.Reader, error) {
+	if contentType := mime.TypeByExtension(path.Ext(urlPath)); contentType != "" {
+		return contentType, reader, nil
+	}
+
+	buffer := make([]byte, sniffLen)
+	n, err := io.ReadFull(reader, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", reader, err
+	}
+	buffer = buffer[:n]
+
+	// rewind seekable readers so they could still be uploaded with known length
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(int64(-n), io.SeekCurrent); err == nil {
+			return http.DetectContentType(buffer), reader, nil
+		}
+	}
+
+	return http.DetectContentType(buffer), io.MultiReader(bytes.NewReader(buffer), reader), nil
+}
+
+// ReaderSize get remaining size of reader without reading it, returns false if the size is unknown
+func ReaderSize(reader io.Reader) (int64, bool) {
+	switch r := reader.(type) {
+	case interface{ Len() int }: // bytes.Buffer, bytes.Reader, strings.Reader
+		return int64(r.Len()), true
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - offset, true
+	case io.Seeker:
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err = r.Seek(offset, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - offset, true
+	}
+	return 0, false
+}
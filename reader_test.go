@@ -0,0 +1,47 @@
+package oss_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qor/oss"
+)
+
+func TestDetectContentType(t *testing.T) {
+	content := "<html><body>sample</body></html>"
+
+	for _, reader := range []io.Reader{strings.NewReader(content), io.MultiReader(strings.NewReader(content))} {
+		contentType, reader, err := oss.DetectContentType("/sample", reader)
+		if err != nil {
+			t.Errorf("No error should happen when detect content type, but got %v", err)
+		}
+
+		if contentType != "text/html; charset=utf-8" {
+			t.Errorf("Content type should be sniffed from content, but got %v", contentType)
+		}
+
+		if buffer, _ := ioutil.ReadAll(reader); string(buffer) != content {
+			t.Errorf("Returned reader should read from beginning, but got %v", string(buffer))
+		}
+	}
+
+	if contentType, _, _ := oss.DetectContentType("/sample.png", strings.NewReader(content)); contentType != "image/png" {
+		t.Errorf("Content type should be detected by extension, but got %v", contentType)
+	}
+}
+
+func TestReaderSize(t *testing.T) {
+	reader := bytes.NewReader([]byte("sample"))
+	reader.Seek(2, io.SeekStart)
+
+	if size, ok := oss.ReaderSize(reader); !ok || size != 4 {
+		t.Errorf("Size of reader should be remaining length 4, but got %v", size)
+	}
+
+	if _, ok := oss.ReaderSize(io.MultiReader(reader)); ok {
+		t.Errorf("Size of stream should be unknown")
+	}
+}
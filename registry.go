@@ -0,0 +1,94 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Driver open a storage with URL, e.g. s3://bucket/prefix?region=us-east-1, credentials are read from the URL's user info
+type Driver func(u *url.URL) (StorageInterface, error)
+
+var (
+	driversMutex sync.RWMutex
+	drivers      = map[string]Driver{}
+)
+
+// Register register driver of URL scheme, storages register their drivers when their packages are imported, e.g.
+//
+//	import _ "github.com/qor/oss/s3"
+//
+// It panics if the scheme is registered twice or driver is nil
+func Register(scheme string, driver Driver) {
+	driversMutex.Lock()
+	defer driversMutex.Unlock()
+
+	if driver == nil {
+		panic("oss: Register driver is nil")
+	}
+	if _, dup := drivers[scheme]; dup {
+		panic("oss: Register called twice for driver " + scheme)
+	}
+	drivers[scheme] = driver
+}
+
+// Drivers get sorted schemes of registered drivers
+func Drivers() []string {
+	driversMutex.RLock()
+	defer driversMutex.RUnlock()
+
+	schemes := make([]string, 0, len(drivers))
+	for scheme := range drivers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open open a storage with URL, the storage is initialized by the driver registered with the URL's scheme, e.g.
+//
+//	storage, err := oss.Open("s3://bucket/uploads?region=us-east-1")
+func Open(rawURL string) (StorageInterface, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// the URL isn't included in the error as it might contain credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("oss: invalid storage URL: %w", err)
+	}
+	return OpenURL(u)
+}
+
+// OpenURL open a storage with parsed URL, could be used to build the URL without escaping its parts
+func OpenURL(u *url.URL) (StorageInterface, error) {
+	if u.Scheme == "" {
+		return nil, errors.New("oss: storage URL has no scheme")
+	}
+
+	driversMutex.RLock()
+	driver, ok := drivers[u.Scheme]
+	driversMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("oss: unknown storage %q (forgotten import?)", u.Scheme)
+	}
+	return driver(u)
+}
+
+// ParseBool parse boolean query parameter key of storage URL, used by drivers, returns false if it is blank
+func ParseBool(storage string, query url.Values, key string) (bool, error) {
+	value := query.Get(key)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &ConfigError{Storage: storage, Field: key, Reason: "is not a boolean", Err: err}
+	}
+	return b, nil
+}
@@ -0,0 +1,46 @@
+package s3
+
+import (
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/qor/oss"
+	"github.com/qor/oss/prefix"
+)
+
+func init() {
+	oss.Register("s3", Open)
+}
+
+// Open initialize S3 storage with URL like s3://ACCESS_ID:ACCESS_KEY@bucket/prefix?region=us-east-1, it is registered as the s3 driver.
+// Supported query parameters are region, endpoint, s3_endpoint, path_style, acl, cache_control, session_token, role_arn and ec2_iam_role,
+// files are saved under the prefix if the URL has a path
+func Open(u *url.URL) (oss.StorageInterface, error) {
+	query := u.Query()
+	config := &Config{
+		AccessID:     u.User.Username(),
+		Region:       query.Get("region"),
+		Bucket:       u.Host,
+		SessionToken: query.Get("session_token"),
+		ACL:          types.ObjectCannedACL(query.Get("acl")),
+		Endpoint:     query.Get("endpoint"),
+		S3Endpoint:   query.Get("s3_endpoint"),
+		CacheControl: query.Get("cache_control"),
+		RoleARN:      query.Get("role_arn"),
+	}
+	config.AccessKey, _ = u.User.Password()
+
+	var err error
+	if config.S3ForcePathStyle, err = oss.ParseBool("s3", query, "path_style"); err != nil {
+		return nil, err
+	}
+	if config.EnableEC2IAMRole, err = oss.ParseBool("s3", query, "ec2_iam_role"); err != nil {
+		return nil, err
+	}
+
+	client, err := NewWithError(config)
+	if err != nil {
+		return nil, err
+	}
+	return prefix.Wrap(client, u.Path), nil
+}
@@ -0,0 +1,127 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/qor/oss"
+)
+
+var _ oss.MultipartUploader = (*Client)(nil)
+
+// InitiateMultipart initiate a multipart upload with CreateMultipartUpload
+func (client Client) InitiateMultipart(ctx context.Context, path string, contentType string) (*oss.MultipartUpload, error) {
+	params := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToS3Key(path)),
+		ACL:    client.Config.ACL,
+	}
+	if contentType != "" {
+		params.ContentType = aws.String(contentType)
+	}
+	if client.Config.CacheControl != "" {
+		params.CacheControl = aws.String(client.Config.CacheControl)
+	}
+
+	output, err := client.S3.CreateMultipartUpload(ctx, params)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.MultipartUpload{Path: path, UploadID: aws.ToString(output.UploadId), ContentType: contentType}, nil
+}
+
+// UploadPart upload a part, the reader should be seekable to be signed and retried by the SDK
+func (client Client) UploadPart(ctx context.Context, upload *oss.MultipartUpload, partNumber int, reader io.Reader, size int64) (*oss.Part, error) {
+	output, err := client.S3.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(client.Config.Bucket),
+		Key:           aws.String(client.ToS3Key(upload.Path)),
+		UploadId:      aws.String(upload.UploadID),
+		PartNumber:    aws.Int32(int32(partNumber)),
+		Body:          reader,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Part{PartNumber: partNumber, ETag: strings.Trim(aws.ToString(output.ETag), `"`), Size: size}, nil
+}
+
+// CompleteMultipart complete a multipart upload with uploaded parts
+func (client Client) CompleteMultipart(ctx context.Context, upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	var completedParts []types.CompletedPart
+	for _, part := range parts {
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       aws.String(`"` + part.ETag + `"`),
+			PartNumber: aws.Int32(int32(part.PartNumber)),
+		})
+	}
+
+	_, err := client.S3.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(client.Config.Bucket),
+		Key:             aws.String(client.ToS3Key(upload.Path)),
+		UploadId:        aws.String(upload.UploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	now := time.Now()
+	return &oss.Object{
+		Path:             upload.Path,
+		Name:             filepath.Base(upload.Path),
+		LastModified:     &now,
+		StorageInterface: client,
+	}, nil
+}
+
+// AbortMultipart abort a multipart upload, uploaded parts will be removed
+func (client Client) AbortMultipart(ctx context.Context, upload *oss.MultipartUpload) error {
+	_, err := client.S3.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(client.Config.Bucket),
+		Key:      aws.String(client.ToS3Key(upload.Path)),
+		UploadId: aws.String(upload.UploadID),
+	})
+	return wrapError(err)
+}
+
+// ListParts list uploaded parts of a multipart upload
+func (client Client) ListParts(ctx context.Context, upload *oss.MultipartUpload) ([]*oss.Part, error) {
+	var (
+		parts  []*oss.Part
+		marker *string
+	)
+
+	for {
+		output, err := client.S3.ListParts(ctx, &s3.ListPartsInput{
+			Bucket:           aws.String(client.Config.Bucket),
+			Key:              aws.String(client.ToS3Key(upload.Path)),
+			UploadId:         aws.String(upload.UploadID),
+			PartNumberMarker: marker,
+		})
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		for _, part := range output.Parts {
+			parts = append(parts, &oss.Part{
+				PartNumber: int(aws.ToInt32(part.PartNumber)),
+				ETag:       strings.Trim(aws.ToString(part.ETag), `"`),
+				Size:       aws.ToInt64(part.Size),
+			})
+		}
+
+		if !aws.ToBool(output.IsTruncated) {
+			return parts, nil
+		}
+		marker = output.NextPartNumberMarker
+	}
+}
@@ -3,19 +3,21 @@ package s3
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
@@ -26,6 +28,16 @@ import (
 	"github.com/qor/oss"
 )
 
+var (
+	_ oss.ContextStorage = (*Client)(nil)
+	_ oss.Stater         = (*Client)(nil)
+	_ oss.RangeGetter    = (*Client)(nil)
+	_ oss.Lister         = (*Client)(nil)
+	_ oss.Copier         = (*Client)(nil)
+	_ oss.URLSigner      = (*Client)(nil)
+	_ oss.BatchDeleter   = (*Client)(nil)
+)
+
 // Client S3 storage
 type Client struct {
 	S3     *s3.Client
@@ -48,13 +60,61 @@ type Config struct {
 	S3ForcePathStyle bool
 	CacheControl     string
 
+	// PartSize and Concurrency are used when uploading files with unknown size in parts, PartSize defaults to 16MB
+	PartSize    int64
+	Concurrency int
+
 	AwsConfig        *aws.Config
 	RoleARN          string
 	EnableEC2IAMRole bool
 }
 
-// New initialize S3 storage
+// New initialize S3 storage, it panics if AWS config couldn't be loaded, use NewWithError to validate config and get the error
 func New(cfg *Config) *Client {
+	client, err := newClient(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// NewWithError validate config and initialize S3 storage, returns *oss.ConfigError if a field of config is invalid
+func NewWithError(cfg *Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return newClient(cfg)
+}
+
+// Validate check fields of config, returns *oss.ConfigError of the first invalid field
+func (cfg Config) Validate() error {
+	switch {
+	case cfg.Bucket == "":
+		return oss.NewConfigError("s3", "Bucket", "is required")
+	case cfg.Region == "" && cfg.AwsConfig == nil:
+		return oss.NewConfigError("s3", "Region", "is required")
+	case cfg.AccessID != "" && cfg.AccessKey == "":
+		return oss.NewConfigError("s3", "AccessKey", "is required if AccessID is set")
+	case cfg.AccessID == "" && cfg.AccessKey != "":
+		return oss.NewConfigError("s3", "AccessID", "is required if AccessKey is set")
+	case cfg.ACL != "" && !slices.Contains(cfg.ACL.Values(), cfg.ACL):
+		return oss.NewConfigError("s3", "ACL", fmt.Sprintf("%q isn't a canned ACL", cfg.ACL))
+	case cfg.PartSize != 0 && cfg.PartSize < 5<<20:
+		return oss.NewConfigError("s3", "PartSize", "should be at least 5MB")
+	case cfg.Concurrency < 0:
+		return oss.NewConfigError("s3", "Concurrency", "should not be negative")
+	}
+
+	if cfg.S3Endpoint != "" {
+		if u, err := url.Parse(cfg.S3Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return &oss.ConfigError{Storage: "s3", Field: "S3Endpoint", Reason: "should be a URL like https://s3.example.com", Err: err}
+		}
+	}
+	return nil
+}
+
+// newClient initialize S3 storage, returns error if AWS config couldn't be loaded
+func newClient(cfg *Config) (*Client, error) {
 	if cfg.ACL == "" {
 		cfg.ACL = types.ObjectCannedACLPublicRead // default ACL
 	}
@@ -86,7 +146,7 @@ func New(cfg *Config) *Client {
 	if cfg.RoleARN != "" {
 		awsCfg, err := config.LoadDefaultConfig(context.TODO())
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.RoleARN)
@@ -98,14 +158,14 @@ func New(cfg *Config) *Client {
 
 		s3Client := s3.NewFromConfig(awsCfg, s3CfgOptions...)
 		client.S3 = s3Client
-		return client
+		return client, nil
 	}
 
 	// use alreay configured aws config
 	if cfg.AwsConfig != nil {
 		s3Client := s3.NewFromConfig(*cfg.AwsConfig, s3CfgOptions...)
 		client.S3 = s3Client
-		return client
+		return client, nil
 	}
 
 	aswCfgOptions := []func(*config.LoadOptions) error{
@@ -128,17 +188,22 @@ func New(cfg *Config) *Client {
 
 	awsConfig, err := config.LoadDefaultConfig(context.TODO(), aswCfgOptions...)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	s3Client := s3.NewFromConfig(awsConfig, s3CfgOptions...)
 	client.S3 = s3Client
-	return client
+	return client, nil
 }
 
 // Get receive file with given path
 func (client Client) Get(path string) (file *os.File, err error) {
-	readCloser, err := client.GetStream(path)
+	return client.GetContext(context.Background(), path)
+}
+
+// GetContext receive file with given path
+func (client Client) GetContext(ctx context.Context, path string) (file *os.File, err error) {
+	readCloser, err := client.GetStreamContext(ctx, path)
 
 	ext := filepath.Ext(path)
 	pattern := fmt.Sprintf("s3*%s", ext)
@@ -156,88 +221,236 @@ func (client Client) Get(path string) (file *os.File, err error) {
 
 // GetStream get file as stream
 func (client Client) GetStream(path string) (io.ReadCloser, error) {
-	getResponse, err := client.S3.GetObject(context.TODO(), &s3.GetObjectInput{
+	return client.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream
+func (client Client) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	getResponse, err := client.S3.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(client.Config.Bucket),
 		Key:    aws.String(client.ToS3Key(path)),
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return getResponse.Body, err
 }
 
+// GetRange get length bytes from offset of the file as stream, negative length reads to the end of the file
+func (client Client) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return client.GetRangeContext(context.Background(), path, offset, length)
+}
+
+// GetRangeContext get length bytes from offset of the file as stream with Range header,
+// if the range is ignored by the server, bytes before offset are discarded
+func (client Client) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.CheckRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	getResponse, err := client.S3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToS3Key(path)),
+		Range:  aws.String(oss.HTTPRange(offset, length)),
+	})
+
+	if err != nil {
+		if err = wrapError(err); errors.Is(err, oss.ErrInvalidRange) && offset == 0 {
+			return http.NoBody, nil // empty file
+		}
+		return nil, err
+	}
+
+	if getResponse.ContentRange == nil {
+		return oss.SkipReadCloser(getResponse.Body, path, offset, length)
+	}
+	return oss.LimitReadCloser(getResponse.Body, length), nil
+}
+
 // Put store a reader into given path
 func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutContext(context.Background(), urlPath, reader)
+}
+
+// PutContext store a reader into given path, the reader is streamed to S3, seekable readers with known size are uploaded with PutObject,
+// others are uploaded in parts with multipart upload, so only one part is buffered in memory
+func (client Client) PutContext(ctx context.Context, urlPath string, reader io.Reader) (*oss.Object, error) {
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
 	key := client.ToS3Key(urlPath)
-	buffer, err := io.ReadAll(reader)
+	fileType, reader, err := oss.DetectContentType(urlPath, reader)
+	if err != nil {
+		return nil, err
+	}
 
-	fileType := mime.TypeByExtension(path.Ext(urlPath))
-	if fileType == "" {
-		fileType = http.DetectContentType(buffer)
+	_, seekable := reader.(io.ReadSeeker)
+	if size, ok := oss.ReaderSize(reader); ok && seekable {
+		err = client.putObject(ctx, key, fileType, reader, size)
+	} else {
+		err = client.putStream(ctx, urlPath, fileType, reader)
 	}
 
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	now := time.Now()
+	return &oss.Object{
+		Path:             urlPath,
+		Name:             filepath.Base(urlPath),
+		LastModified:     &now,
+		StorageInterface: client,
+	}, nil
+}
+
+// putObject upload reader with PutObject
+func (client Client) putObject(ctx context.Context, key, fileType string, reader io.Reader, size int64) error {
 	params := &s3.PutObjectInput{
 		Bucket:        aws.String(client.Config.Bucket), // required
 		Key:           aws.String(key),                  // required
 		ACL:           client.Config.ACL,
-		Body:          bytes.NewReader(buffer),
-		ContentLength: aws.Int64(int64(len(buffer))),
+		Body:          reader,
+		ContentLength: aws.Int64(size),
 		ContentType:   aws.String(fileType),
 	}
 	if client.Config.CacheControl != "" {
 		params.CacheControl = aws.String(client.Config.CacheControl)
 	}
 
-	_, err = client.S3.PutObject(context.Background(), params)
+	_, err := client.S3.PutObject(ctx, params)
+	return err
+}
 
-	now := time.Now()
-	return &oss.Object{
-		Path:             urlPath,
-		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
-		StorageInterface: client,
-	}, err
+// putStream upload reader with unknown size, if it fits into one part, it is uploaded with PutObject,
+// otherwise with multipart upload, parts are uploaded concurrently
+func (client Client) putStream(ctx context.Context, urlPath, fileType string, reader io.Reader) error {
+	buffer := make([]byte, client.partSize())
+	n, err := io.ReadFull(reader, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return client.putObject(ctx, client.ToS3Key(urlPath), fileType, bytes.NewReader(buffer[:n]), int64(n))
+	} else if err != nil {
+		return err
+	}
+
+	_, err = oss.UploadMultipart(ctx, client, urlPath, io.MultiReader(bytes.NewReader(buffer), reader), oss.MultipartOptions{
+		PartSize:    client.partSize(),
+		Concurrency: client.Config.Concurrency,
+		ContentType: fileType,
+	})
+	return err
+}
+
+// partSize size of parts when uploading streams, S3 requires at least 5MB for all parts except the last one
+func (client Client) partSize() int64 {
+	if client.Config.PartSize >= 5<<20 {
+		return client.Config.PartSize
+	}
+	return 16 << 20
+}
+
+// Stat get file's information with HeadObject
+func (client Client) Stat(path string) (*oss.ObjectInfo, error) {
+	return client.StatContext(context.Background(), path)
+}
+
+// StatContext get file's information with HeadObject
+func (client Client) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	headResponse, err := client.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToS3Key(path)),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	metadata := map[string]string{}
+	for key, value := range headResponse.Metadata {
+		metadata[strings.ToLower(key)] = value
+	}
+
+	return &oss.ObjectInfo{
+		Path:         path,
+		Name:         filepath.Base(path),
+		Size:         aws.ToInt64(headResponse.ContentLength),
+		ETag:         strings.Trim(aws.ToString(headResponse.ETag), `"`),
+		ContentType:  aws.ToString(headResponse.ContentType),
+		CacheControl: aws.ToString(headResponse.CacheControl),
+		LastModified: headResponse.LastModified,
+		StorageClass: string(headResponse.StorageClass),
+		Metadata:     metadata,
+	}, nil
 }
 
 // Delete delete file
 func (client Client) Delete(path string) error {
-	_, err := client.S3.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+	return client.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext delete file
+func (client Client) DeleteContext(ctx context.Context, path string) error {
+	_, err := client.S3.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(client.Config.Bucket),
 		Key:    aws.String(client.ToS3Key(path)),
 	})
-	return err
+	return wrapError(err)
 }
 
-// DeleteObjects delete files in bulk
+// DeleteObjects delete files in bulk, the error joins errors of all failed files, use DeleteMany to get failed files
 func (client Client) DeleteObjects(paths []string) (err error) {
-	var objs []types.ObjectIdentifier
-	for _, v := range paths {
-		var obj types.ObjectIdentifier
-		obj.Key = aws.String(strings.TrimPrefix(client.ToS3Key(v), "/"))
-		objs = append(objs, obj)
-	}
-	input := &s3.DeleteObjectsInput{
-		Bucket: aws.String(client.Config.Bucket),
-		Delete: &types.Delete{
-			Objects: objs,
-		},
-	}
+	_, err = client.DeleteManyContext(context.Background(), paths)
+	return err
+}
 
-	_, err = client.S3.DeleteObjects(context.Background(), input)
-	if err != nil {
-		return
-	}
-	return
+// DeleteMany delete files in batches
+func (client Client) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return client.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete files in batches of 1000 files with DeleteObjects concurrently, batches are deleted in quiet mode,
+// so only failed files are returned by S3
+func (client Client) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	return oss.DeleteInBatches(ctx, paths, oss.BatchOptions{}, func(ctx context.Context, batch []string) (oss.BatchResult, error) {
+		var objects []types.ObjectIdentifier
+		for _, path := range batch {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(strings.TrimPrefix(client.ToS3Key(path), "/"))})
+		}
+
+		output, err := client.S3.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(client.Config.Bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return oss.BatchResult{}, wrapError(err)
+		}
+
+		failures := map[string]error{}
+		for _, deleteErr := range output.Errors {
+			failures[aws.ToString(deleteErr.Key)] = wrapDeleteError(deleteErr)
+		}
+
+		var result oss.BatchResult
+		for i, path := range batch {
+			if err, ok := failures[aws.ToString(objects[i].Key)]; ok {
+				result.Failures = append(result.Failures, oss.BatchFailure{Path: path, Err: err})
+			} else {
+				result.Deleted = append(result.Deleted, path)
+			}
+		}
+		return result, nil
+	})
 }
 
 // List list all objects under current path
 func (client Client) List(path string) ([]*oss.Object, error) {
+	return client.ListContext(context.Background(), path)
+}
+
+// ListContext list all objects under current path
+func (client Client) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
 	var objects []*oss.Object
 	var prefix string
 	var continuationToken *string
@@ -247,13 +460,13 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 	}
 
 	for {
-		listObjectsResponse, err := client.S3.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
+		listObjectsResponse, err := client.S3.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
 			Bucket:            aws.String(client.Config.Bucket),
 			Prefix:            aws.String(prefix),
 			ContinuationToken: continuationToken,
 		})
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 
 		for _, content := range listObjectsResponse.Contents {
@@ -275,6 +488,46 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 	return objects, nil
 }
 
+// ListPage list a page of objects matched options with ListObjectsV2
+func (client Client) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(client.Config.Bucket),
+		Prefix:  aws.String(client.ToS3Key(options.Prefix)),
+		MaxKeys: aws.Int32(int32(options.GetMaxKeys())),
+	}
+	if delimiter := options.GetDelimiter(); delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
+	if options.StartAfter != "" {
+		input.StartAfter = aws.String(client.ToS3Key(options.StartAfter))
+	}
+	if options.ContinuationToken != "" {
+		input.ContinuationToken = aws.String(options.ContinuationToken)
+	}
+
+	listObjectsResponse, err := client.S3.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	result := &oss.ListResult{
+		IsTruncated:           aws.ToBool(listObjectsResponse.IsTruncated),
+		NextContinuationToken: aws.ToString(listObjectsResponse.NextContinuationToken),
+	}
+	for _, content := range listObjectsResponse.Contents {
+		result.Objects = append(result.Objects, &oss.Object{
+			Path:             "/" + client.ToS3Key(*content.Key),
+			Name:             filepath.Base(*content.Key),
+			LastModified:     content.LastModified,
+			StorageInterface: client,
+		})
+	}
+	for _, commonPrefix := range listObjectsResponse.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, "/"+aws.ToString(commonPrefix.Prefix))
+	}
+	return result, nil
+}
+
 // GetEndpoint get endpoint, FileSystem's endpoint is /
 func (client Client) GetEndpoint() string {
 	if client.Config.Endpoint != "" {
@@ -332,18 +585,15 @@ func (client Client) ToRelativePath(urlPath string) string {
 
 // GetURL get public accessible URL
 func (client Client) GetURL(path string) (url string, err error) {
+	return client.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get public accessible URL
+func (client Client) GetURLContext(ctx context.Context, path string) (url string, err error) {
 	if client.Config.Endpoint == "" {
 		if client.Config.ACL == types.ObjectCannedACLPrivate || client.Config.ACL == types.ObjectCannedACLAuthenticatedRead {
-			presignClient := s3.NewPresignClient(client.S3)
-			presignedGetURL, err := presignClient.PresignGetObject(context.TODO(), &s3.GetObjectInput{
-				Bucket: aws.String(client.Config.Bucket),
-				Key:    aws.String(client.ToS3Key(path)),
-			}, func(opts *s3.PresignOptions) {
-				opts.Expires = 1 * time.Hour
-			})
-
-			if err == nil && presignedGetURL != nil {
-				return presignedGetURL.URL, nil
+			if presignedGetURL, err := client.GetSignedURLContext(ctx, path, oss.URLOptions{}); err == nil {
+				return presignedGetURL, nil
 			}
 		}
 	}
@@ -351,12 +601,106 @@ func (client Client) GetURL(path string) (url string, err error) {
 	return path, nil
 }
 
+// GetSignedURL generate a pre-signed URL of the file
+func (client Client) GetSignedURL(path string, options oss.URLOptions) (string, error) {
+	return client.GetSignedURLContext(context.Background(), path, options)
+}
+
+// GetSignedURLContext generate a pre-signed URL of the file, supports GET, HEAD, PUT and DELETE methods.
+// PUT URLs are signed with the configured ACL and cache control, so uploads have to send them as x-amz-acl and Cache-Control headers,
+// send the file's content type as Content-Type header, e.g.
+//
+//	fetch(url, {method: "PUT", headers: {"x-amz-acl": "public-read", "Content-Type": file.type}, body: file})
+func (client Client) GetSignedURLContext(ctx context.Context, path string, options oss.URLOptions) (string, error) {
+	var (
+		presignClient = s3.NewPresignClient(client.S3)
+		request       *v4.PresignedHTTPRequest
+		err           error
+		bucket        = aws.String(client.Config.Bucket)
+		key           = aws.String(client.ToS3Key(path))
+		expires       = s3.WithPresignExpires(options.GetExpires())
+	)
+
+	switch options.GetMethod() {
+	case http.MethodGet:
+		input := &s3.GetObjectInput{Bucket: bucket, Key: key}
+		if options.ResponseContentDisposition != "" {
+			input.ResponseContentDisposition = aws.String(options.ResponseContentDisposition)
+		}
+		if options.ResponseContentType != "" {
+			input.ResponseContentType = aws.String(options.ResponseContentType)
+		}
+		request, err = presignClient.PresignGetObject(ctx, input, expires)
+	case http.MethodHead:
+		request, err = presignClient.PresignHeadObject(ctx, &s3.HeadObjectInput{Bucket: bucket, Key: key}, expires)
+	case http.MethodPut:
+		input := &s3.PutObjectInput{Bucket: bucket, Key: key, ACL: client.Config.ACL}
+		if client.Config.CacheControl != "" {
+			input.CacheControl = aws.String(client.Config.CacheControl)
+		}
+		request, err = presignClient.PresignPutObject(ctx, input, expires)
+	case http.MethodDelete:
+		request, err = presignClient.PresignDeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: key}, expires)
+	default:
+		return "", fmt.Errorf("s3: signed URL doesn't support method %v", options.Method)
+	}
+
+	if err != nil {
+		return "", err
+	}
+	return request.URL, nil
+}
+
 // Copy copy s3 file from "from" to "to"
-func (client Client) Copy(from, to string) (err error) {
-	_, err = client.S3.CopyObject(context.Background(), &s3.CopyObjectInput{
+func (client Client) Copy(from, to string) error {
+	return client.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy s3 file from "from" to "to" with CopyObject, the copy gets the configured ACL
+func (client Client) CopyContext(ctx context.Context, from, to string) error {
+	source := url.URL{Path: client.Config.Bucket + "/" + client.ToS3Key(from)}
+	_, err := client.S3.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     aws.String(client.Config.Bucket),
-		CopySource: aws.String(from),
-		Key:        aws.String(to),
+		CopySource: aws.String(source.EscapedPath()),
+		Key:        aws.String(client.ToS3Key(to)),
+		ACL:        client.Config.ACL,
 	})
-	return
+	return wrapError(err)
+}
+
+// Move move s3 file from "from" to "to", S3 doesn't support renaming, so the file is copied then deleted
+func (client Client) Move(from, to string) error {
+	return client.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move s3 file from "from" to "to"
+func (client Client) MoveContext(ctx context.Context, from, to string) error {
+	if client.ToS3Key(from) == client.ToS3Key(to) {
+		return nil
+	}
+	if err := client.CopyContext(ctx, from, to); err != nil {
+		return err
+	}
+	return client.DeleteContext(ctx, from)
+}
+
+// wrapDeleteError wrap error of a file failed in DeleteObjects with oss errors by the error code
+func wrapDeleteError(deleteErr types.Error) error {
+	err := fmt.Errorf("s3: %v: %v", aws.ToString(deleteErr.Code), aws.ToString(deleteErr.Message))
+	switch aws.ToString(deleteErr.Code) {
+	case "AccessDenied":
+		return oss.WrapError(oss.ErrPermission, err)
+	case "NoSuchKey", "NoSuchBucket":
+		return oss.WrapError(oss.ErrNotExist, err)
+	}
+	return err
+}
+
+// wrapError wrap S3 errors with oss errors by the response's status code
+func wrapError(err error) error {
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		return oss.WrapHTTPError(responseErr.HTTPStatusCode(), err)
+	}
+	return err
 }
@@ -1,13 +1,23 @@
 package s3_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/jinzhu/configor"
+	"github.com/qor/oss"
 	"github.com/qor/oss/s3"
 	"github.com/qor/oss/tests"
+	"github.com/qor/oss/tests/fakes3"
 )
 
 type Config struct {
@@ -30,6 +40,10 @@ func init() {
 }
 
 func TestAll(t *testing.T) {
+	if config.Bucket == "" {
+		t.Skip("QOR_AWS_BUCKET is not set, skip testing with S3")
+	}
+
 	fmt.Println("testing S3 with public ACL")
 	tests.TestAll(client, t)
 
@@ -42,6 +56,130 @@ func TestAll(t *testing.T) {
 	tests.TestAll(authenticatedReadClient, t)
 }
 
+// newFakeClient initialize S3 storage with a fake S3 server
+func newFakeClient(t *testing.T, cfg *s3.Config) (*s3.Client, *fakes3.Server) {
+	server := fakes3.New()
+	t.Cleanup(server.Close)
+
+	cfg.AccessID, cfg.AccessKey, cfg.Region, cfg.Bucket = "access_id", "access_key", "us-east-1", "fake-bucket"
+	cfg.CustomEndpointResolver = server.EndpointResolver()
+	return s3.New(cfg), server
+}
+
+func TestAllWithFakeServer(t *testing.T) {
+	for _, acl := range []types.ObjectCannedACL{types.ObjectCannedACLPublicRead, types.ObjectCannedACLPrivate, types.ObjectCannedACLAuthenticatedRead} {
+		t.Run(string(acl), func(t *testing.T) {
+			client, server := newFakeClient(t, &s3.Config{ACL: acl})
+			tests.TestAll(client, t)
+
+			client.Put("/acl.txt", bytes.NewReader([]byte("acl")))
+			if object, ok := server.Object("fake-bucket", "acl.txt"); !ok || object.ACL != string(acl) {
+				t.Errorf("Object should be saved with ACL %v, but got %+v", acl, object)
+			}
+		})
+	}
+}
+
+func TestIgnoredRange(t *testing.T) {
+	client, server := newFakeClient(t, &s3.Config{})
+	server.IgnoreRange()
+	tests.TestAll(client, t)
+}
+
+func TestSignedPutURL(t *testing.T) {
+	client, server := newFakeClient(t, &s3.Config{ACL: types.ObjectCannedACLPublicRead})
+
+	signedURL, err := client.GetSignedURLContext(context.Background(), "/uploads/avatar.png", oss.URLOptions{Method: http.MethodPut, Expires: time.Minute})
+	if err != nil {
+		t.Fatalf("No error should happen when sign PUT URL, but got %v", err)
+	}
+
+	if u, err := url.Parse(signedURL); err != nil || !strings.Contains(u.Query().Get("X-Amz-SignedHeaders"), "x-amz-acl") {
+		t.Errorf("ACL should be signed into PUT URL, but got %v", signedURL)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, signedURL, strings.NewReader("avatar"))
+	req.Header.Set("X-Amz-Acl", string(types.ObjectCannedACLPublicRead))
+	req.Header.Set("Content-Type", "image/png")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Upload with signed PUT URL should succeed, but got %v, %v", resp, err)
+	} else {
+		resp.Body.Close()
+	}
+
+	if object, ok := server.Object("fake-bucket", "uploads/avatar.png"); !ok || object.ACL != string(types.ObjectCannedACLPublicRead) || object.ContentType != "image/png" {
+		t.Errorf("Uploaded object should keep ACL and content type, but got %+v", object)
+	}
+}
+
+func TestPutStreamInParts(t *testing.T) {
+	client, server := newFakeClient(t, &s3.Config{PartSize: 5 << 20, Concurrency: 2})
+	content := bytes.Repeat([]byte("0123456789"), 1<<20+10)
+
+	if _, err := client.Put("/large.txt", io.MultiReader(bytes.NewReader(content))); err != nil {
+		t.Fatalf("No error should happen when put stream in parts, but got %v", err)
+	}
+
+	if object, ok := server.Object("fake-bucket", "large.txt"); !ok || !bytes.Equal(object.Content, content) {
+		t.Errorf("Stream should be uploaded completely")
+	} else if object.ETag[len(object.ETag)-2:] != "-3" {
+		t.Errorf("Stream should be uploaded in 3 parts, but got ETag %v", object.ETag)
+	}
+	if server.Uploads() != 0 {
+		t.Errorf("Multipart upload should be completed, but got %v uploads", server.Uploads())
+	}
+}
+
+func TestDeleteObjects(t *testing.T) {
+	client, _ := newFakeClient(t, &s3.Config{})
+	for _, path := range []string{"/a.txt", "/b.txt", "/c.txt"} {
+		client.Put(path, bytes.NewReader([]byte(path)))
+	}
+
+	if err := client.DeleteObjects([]string{"/a.txt", "/b.txt"}); err != nil {
+		t.Fatalf("No error should happen when delete objects, but got %v", err)
+	}
+
+	if objects, err := client.List(""); err != nil || len(objects) != 1 || objects[0].Path != "/c.txt" {
+		t.Errorf("Only /c.txt should be left, but got %v, %v", objects, err)
+	}
+	if _, err := oss.GetRange(context.Background(), client, "/a.txt", 0, 1); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Deleted object should not exist, but got %v", err)
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	client, server := newFakeClient(t, &s3.Config{})
+	server.DenyDelete(func(bucket, key string) bool { return strings.HasPrefix(key, "denied/") })
+
+	var paths []string
+	for i := 0; i < 2500; i++ {
+		paths = append(paths, fmt.Sprintf("/batch/%04d.txt", i))
+	}
+	paths = append(paths, "/denied/a.txt")
+	for _, path := range paths {
+		client.Put(path, bytes.NewReader([]byte(path)))
+	}
+
+	result, err := client.DeleteMany(paths)
+	if !errors.Is(err, oss.ErrPermission) {
+		t.Errorf("Error should contain failed files, but got %v", err)
+	}
+	if len(result.Deleted) != 2500 || result.Deleted[2499] != "/batch/2499.txt" {
+		t.Errorf("Files should be deleted in batches, but got %v deleted", len(result.Deleted))
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Path != "/denied/a.txt" || !errors.Is(result.Failures[0].Err, oss.ErrPermission) {
+		t.Errorf("Denied file should be failed, but got %+v", result.Failures)
+	}
+
+	if objects, err := client.List("/batch"); err != nil || len(objects) != 0 {
+		t.Errorf("Deleted files should not be left, but got %v, %v", len(objects), err)
+	}
+	if _, ok := server.Object("fake-bucket", "denied/a.txt"); !ok {
+		t.Errorf("Denied file should not be deleted")
+	}
+}
+
 func TestToRelativePath(t *testing.T) {
 	urlMap := map[string]string{
 		"https://mybucket.s3.amazonaws.com/myobject.ext": "/myobject.ext",
@@ -92,3 +230,59 @@ func TestToRelativePathWithS3ForcePathStyle(t *testing.T) {
 		}
 	}
 }
+
+func TestNewWithError(t *testing.T) {
+	valid := s3.Config{AccessID: "id", AccessKey: "key", Region: "us-east-1", Bucket: "bucket"}
+	invalidConfigs := map[string]func(cfg *s3.Config){
+		"Bucket":      func(cfg *s3.Config) { cfg.Bucket = "" },
+		"Region":      func(cfg *s3.Config) { cfg.Region = "" },
+		"AccessKey":   func(cfg *s3.Config) { cfg.AccessKey = "" },
+		"ACL":         func(cfg *s3.Config) { cfg.ACL = "public" },
+		"PartSize":    func(cfg *s3.Config) { cfg.PartSize = 1 << 20 },
+		"Concurrency": func(cfg *s3.Config) { cfg.Concurrency = -1 },
+		"S3Endpoint":  func(cfg *s3.Config) { cfg.S3Endpoint = "localhost:9000" },
+	}
+
+	for field, invalidate := range invalidConfigs {
+		cfg := valid
+		invalidate(&cfg)
+
+		var configErr *oss.ConfigError
+		if _, err := s3.NewWithError(&cfg); !errors.As(err, &configErr) || configErr.Field != field {
+			t.Errorf("Config with invalid %v should fail with oss.ConfigError of it, but got %v", field, err)
+		}
+	}
+
+	if client, err := s3.NewWithError(&valid); err != nil || client == nil {
+		t.Errorf("No error should happen with valid config, but got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	server := fakes3.New()
+	t.Cleanup(server.Close)
+
+	storage, err := oss.Open("s3://access_id:access_key@fake-bucket/uploads?region=us-east-1&path_style=true&acl=private&s3_endpoint=" + url.QueryEscape(server.URL))
+	if err != nil {
+		t.Fatalf("No error should happen when open S3 storage with URL, but got %v", err)
+	}
+
+	storage.Put("/prefix.txt", strings.NewReader("prefix"))
+	// keys of path style clients start with /
+	if object, ok := server.Object("fake-bucket", "/uploads/prefix.txt"); !ok || object.ACL != string(types.ObjectCannedACLPrivate) {
+		t.Errorf("Object should be saved under prefix with ACL of the URL, but got %+v", object)
+	}
+	if stream, err := storage.GetStream("/prefix.txt"); err != nil {
+		t.Errorf("No error should happen when get file, but got %v", err)
+	} else if content, _ := io.ReadAll(stream); string(content) != "prefix" {
+		t.Errorf("File's content should be prefix, but got %s", content)
+	}
+
+	var configErr *oss.ConfigError
+	if _, err := oss.Open("s3://fake-bucket?region=us-east-1&path_style=maybe"); !errors.As(err, &configErr) || configErr.Field != "path_style" {
+		t.Errorf("Invalid boolean parameter should fail with oss.ConfigError, but got %v", err)
+	}
+	if _, err := oss.Open("s3://fake-bucket"); !errors.As(err, &configErr) || configErr.Field != "Region" {
+		t.Errorf("URL without region should fail with oss.ConfigError, but got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package tencent
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/qor/oss"
+)
+
+// ServiceError error returned by COS
+type ServiceError struct {
+	XMLName    xml.Name `xml:"Error"`
+	Code       string   `xml:"Code"`
+	Message    string   `xml:"Message"`
+	Resource   string   `xml:"Resource"`
+	RequestID  string   `xml:"RequestId"`
+	StatusCode int      `xml:"-"`
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("cos: service returned error: StatusCode=%d, ErrorCode=%s, ErrorMessage=%q, RequestId=%s", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// responseError build error from a failed response, it is wrapped with oss errors by the status code
+func responseError(resp *http.Response) error {
+	serviceErr := &ServiceError{StatusCode: resp.StatusCode}
+	if body, err := ioutil.ReadAll(resp.Body); err == nil && len(body) > 0 {
+		if xml.Unmarshal(body, serviceErr) != nil {
+			serviceErr.Message = string(body)
+		}
+	}
+	if serviceErr.Message == "" {
+		serviceErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return oss.WrapHTTPError(resp.StatusCode, serviceErr)
+}
@@ -0,0 +1,87 @@
+package tencent
+
+import (
+	"context"
+	"encoding/xml"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/qor/oss"
+)
+
+var _ oss.Lister = (*Client)(nil)
+
+type listBucketResult struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	IsTruncated bool     `xml:"IsTruncated"`
+	NextMarker  string   `xml:"NextMarker"`
+	Contents    []struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int64  `xml:"Size"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
+
+// ListPage list a page of objects matched options with COS GET Bucket API, the continuation token is the marker of next page
+func (client Client) ListPage(ctx context.Context, options oss.ListOptions) (*oss.ListResult, error) {
+	query := url.Values{}
+	query.Set("prefix", client.ToRelativePath(options.Prefix))
+	query.Set("max-keys", strconv.Itoa(options.GetMaxKeys()))
+	if delimiter := options.GetDelimiter(); delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if options.ContinuationToken != "" {
+		query.Set("marker", options.ContinuationToken)
+	} else if options.StartAfter != "" {
+		query.Set("marker", client.ToRelativePath(options.StartAfter))
+	}
+
+	req, err := client.newRequest(ctx, "GET", "", query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var listResult listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&listResult); err != nil {
+		return nil, err
+	}
+
+	result := &oss.ListResult{IsTruncated: listResult.IsTruncated}
+	for _, content := range listResult.Contents {
+		object := &oss.Object{
+			Path:             "/" + content.Key,
+			Name:             filepath.Base(content.Key),
+			StorageInterface: client,
+		}
+		if lastModified, err := time.Parse(time.RFC3339, content.LastModified); err == nil {
+			object.LastModified = &lastModified
+		}
+		result.Objects = append(result.Objects, object)
+		result.NextContinuationToken = content.Key
+	}
+	for _, commonPrefix := range listResult.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, "/"+commonPrefix.Prefix)
+		if commonPrefix.Prefix > result.NextContinuationToken {
+			result.NextContinuationToken = commonPrefix.Prefix
+		}
+	}
+
+	if !result.IsTruncated {
+		result.NextContinuationToken = ""
+	} else if listResult.NextMarker != "" {
+		result.NextContinuationToken = listResult.NextMarker
+	}
+	return result, nil
+}
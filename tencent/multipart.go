@@ -0,0 +1,194 @@
+package tencent
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/qor/oss"
+)
+
+var _ oss.MultipartUploader = (*Client)(nil)
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type listPartsResult struct {
+	XMLName              xml.Name `xml:"ListPartsResult"`
+	IsTruncated          bool     `xml:"IsTruncated"`
+	NextPartNumberMarker int      `xml:"NextPartNumberMarker"`
+	Parts                []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+		Size       int64  `xml:"Size"`
+	} `xml:"Part"`
+}
+
+// InitiateMultipart initiate a multipart upload with COS Initiate Multipart Upload API
+func (client Client) InitiateMultipart(ctx context.Context, path string, contentType string) (*oss.MultipartUpload, error) {
+	req, err := client.newRequest(ctx, "POST", path, "uploads", nil)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	client.setACL(req)
+
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := initiateMultipartUploadResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &oss.MultipartUpload{Path: path, UploadID: result.UploadID, ContentType: contentType}, nil
+}
+
+// UploadPart upload a part
+func (client Client) UploadPart(ctx context.Context, upload *oss.MultipartUpload, partNumber int, reader io.Reader, size int64) (*oss.Part, error) {
+	// parts are sent with known length and could be rewound when retried, so readers other than *bytes.Reader are buffered,
+	// empty parts are sent without body, as COS rejects empty chunked bodies
+	var body io.Reader = http.NoBody
+	if size > 0 {
+		part, ok := reader.(*bytes.Reader)
+		if !ok || int64(part.Len()) != size {
+			data, err := ioutil.ReadAll(io.LimitReader(reader, size))
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(data)) != size {
+				return nil, io.ErrUnexpectedEOF
+			}
+			part = bytes.NewReader(data)
+		}
+		body = part
+	}
+
+	query := url.Values{"partNumber": {fmt.Sprint(partNumber)}, "uploadId": {upload.UploadID}}
+	req, err := client.newRequest(ctx, "PUT", upload.Path, query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return &oss.Part{PartNumber: partNumber, ETag: strings.Trim(resp.Header.Get("ETag"), `"`), Size: size}, nil
+}
+
+// CompleteMultipart complete a multipart upload with uploaded parts
+func (client Client) CompleteMultipart(ctx context.Context, upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	body := completeMultipartUpload{}
+	for _, part := range parts {
+		body.Parts = append(body.Parts, completePart{PartNumber: part.PartNumber, ETag: `"` + part.ETag + `"`})
+	}
+
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.newRequest(ctx, "POST", upload.Path, url.Values{"uploadId": {upload.UploadID}}.Encode(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	now := time.Now()
+	return &oss.Object{
+		Path:             upload.Path,
+		Name:             filepath.Base(upload.Path),
+		LastModified:     &now,
+		StorageInterface: client,
+	}, nil
+}
+
+// AbortMultipart abort a multipart upload, uploaded parts will be removed
+func (client Client) AbortMultipart(ctx context.Context, upload *oss.MultipartUpload) error {
+	req, err := client.newRequest(ctx, "DELETE", upload.Path, url.Values{"uploadId": {upload.UploadID}}.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ListParts list uploaded parts of a multipart upload
+func (client Client) ListParts(ctx context.Context, upload *oss.MultipartUpload) ([]*oss.Part, error) {
+	var (
+		parts  []*oss.Part
+		marker int
+	)
+
+	for {
+		query := url.Values{"uploadId": {upload.UploadID}}
+		if marker > 0 {
+			query.Set("part-number-marker", fmt.Sprint(marker))
+		}
+
+		req, err := client.newRequest(ctx, "GET", upload.Path, query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		result := listPartsResult{}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, part := range result.Parts {
+			parts = append(parts, &oss.Part{PartNumber: part.PartNumber, ETag: strings.Trim(part.ETag, `"`), Size: part.Size})
+		}
+
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
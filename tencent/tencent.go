@@ -1,22 +1,34 @@
 package tencent
 
 import (
-	"os"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"github.com/qor/oss"
 	"io"
-	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
-	"time"
-	"errors"
-	"strings"
-	"bytes"
 	"regexp"
-	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var _ oss.StorageInterface = (*Client)(nil)
+var (
+	_ oss.ContextStorage = (*Client)(nil)
+	_ oss.Stater         = (*Client)(nil)
+	_ oss.RangeGetter    = (*Client)(nil)
+	_ oss.Copier         = (*Client)(nil)
+	_ oss.URLSigner      = (*Client)(nil)
+	_ oss.BatchDeleter   = (*Client)(nil)
+)
 
 type Config struct {
 	AppID     string
@@ -24,9 +36,20 @@ type Config struct {
 	AccessKey string
 	Region    string
 	Bucket    string
-	ACL       string
-	CORS      string
-	Endpoint  string
+	// ACL ACL of uploaded objects, like private, public-read, files are accessed with pre-signed URLs if it is private
+	ACL string
+	// CORS allowed origins of the bucket separated by comma, like "https://example.com", New doesn't apply it, call SetCORS once to apply
+	CORS string
+	// Endpoint domain of the bucket, or the bucket's URL with scheme, like "http://127.0.0.1:8080", requests are sent with HTTPS by default
+	Endpoint string
+	// HTTPClient client to send requests, default to a client with Transport
+	HTTPClient *http.Client
+	// Transport transport of the default client, could be used to plug in proxies or instrumentation
+	Transport http.RoundTripper
+	// Timeout timeout of each attempt of requests, including reading the response body like http.Client's Timeout, no timeout if zero
+	Timeout time.Duration
+	// MaxRetries retries of requests failed with 5xx or 429, default 3, negative disables retries
+	MaxRetries int
 }
 
 type Client struct {
@@ -34,16 +57,80 @@ type Client struct {
 	Client *http.Client
 }
 
+const defaultMaxRetries = 3
+
 func New(conf *Config) *Client {
-	return &Client{conf, &http.Client{}}
+	httpClient := conf.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: conf.Transport}
+	}
+	return &Client{conf, httpClient}
+}
+
+// NewWithError validate config and initialize Tencent COS storage, returns *oss.ConfigError if a field of config is invalid
+func NewWithError(conf *Config) (*Client, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	return New(conf), nil
 }
 
+// Validate check fields of config, returns *oss.ConfigError of the first invalid field,
+// Bucket and Region are only required if Endpoint isn't a URL
+func (conf Config) Validate() error {
+	switch {
+	case conf.AccessID == "":
+		return oss.NewConfigError("tencent", "AccessID", "is required")
+	case conf.AccessKey == "":
+		return oss.NewConfigError("tencent", "AccessKey", "is required")
+	case conf.Timeout < 0:
+		return oss.NewConfigError("tencent", "Timeout", "should not be negative")
+	}
+
+	switch conf.ACL {
+	case "", "default", "private", "public-read", "public-read-write":
+	default:
+		return oss.NewConfigError("tencent", "ACL", fmt.Sprintf("%q isn't a canned ACL", conf.ACL))
+	}
+
+	if strings.Contains(conf.Endpoint, "://") {
+		if u, err := url.Parse(conf.Endpoint); err != nil || u.Host == "" {
+			return &oss.ConfigError{Storage: "tencent", Field: "Endpoint", Reason: "should be a URL like http://127.0.0.1:8080", Err: err}
+		}
+		return nil
+	}
+
+	switch {
+	case conf.Bucket == "":
+		return oss.NewConfigError("tencent", "Bucket", "is required")
+	case conf.Region == "":
+		return oss.NewConfigError("tencent", "Region", "is required")
+	}
+	return nil
+}
+
+// getUrl get URL of the bucket, Endpoint with scheme like "http://127.0.0.1:8080" is used as the bucket's URL
 func (client Client) getUrl() string {
-	return fmt.Sprintf("http://%s.cos.%s.myqcloud.com/", client.Config.Bucket, client.Config.Region)
+	if strings.Contains(client.Config.Endpoint, "://") {
+		return strings.TrimSuffix(client.Config.Endpoint, "/") + "/"
+	}
+	return fmt.Sprintf("https://%s.cos.%s.myqcloud.com/", client.Config.Bucket, client.Config.Region)
+}
+
+// getHost get host of the bucket's URL, which is signed in requests
+func (client Client) getHost() string {
+	if u, err := url.Parse(client.getUrl()); err == nil {
+		return u.Host
+	}
+	return client.GetEndpoint()
 }
 
 func (client Client) Get(path string) (file *os.File, err error) {
-	readCloser, err := client.GetStream(path)
+	return client.GetContext(context.Background(), path)
+}
+
+func (client Client) GetContext(ctx context.Context, path string) (file *os.File, err error) {
+	readCloser, err := client.GetStreamContext(ctx, path)
 	if err == nil {
 		if file, err = ioutil.TempFile("/tmp", "tencent"); err == nil {
 			defer readCloser.Close()
@@ -68,49 +155,86 @@ func (client Client) ToRelativePath(urlPath string) string {
 }
 
 func (client Client) GetStream(path string) (io.ReadCloser, error) {
-	resp, err := http.Get(fmt.Sprintf("%s%s", client.getUrl(), client.ToRelativePath(path)))
+	return client.GetStreamContext(context.Background(), path)
+}
+
+// GetStreamContext get file as stream with a signed request, so files in private buckets could be read
+func (client Client) GetStreamContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	req, err := client.newRequest(ctx, "GET", path, "", nil)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil,errors.New("get file fail")
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
 	}
 	return resp.Body, nil
 }
 
+func (client Client) GetRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return client.GetRangeContext(context.Background(), path, offset, length)
+}
+
+func (client Client) GetRangeContext(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.CheckRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	req, err := client.newRequest(ctx, "GET", path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", oss.HTTPRange(offset, length))
+	resp, err := client.do(req)
+	if err != nil {
+		if errors.Is(err, oss.ErrInvalidRange) && offset == 0 {
+			return http.NoBody, nil // empty file
+		}
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		// the whole object is returned if the range is ignored
+		return oss.SkipReadCloser(resp.Body, path, offset, length)
+	}
+	return oss.LimitReadCloser(resp.Body, length), nil
+}
+
 func (client Client) Put(path string, body io.Reader) (*oss.Object, error) {
+	return client.PutContext(context.Background(), path, body)
+}
+
+func (client Client) PutContext(ctx context.Context, path string, body io.Reader) (*oss.Object, error) {
 	if seeker, ok := body.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
-	switch body.(type) {
-	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
-	default:
-		if body != nil {
-			b, err := ioutil.ReadAll(body)
-			if err != nil {
-				return nil, err
-			}
-			body = bytes.NewReader(b)
-		}
+	if body == nil {
+		body = bytes.NewReader(nil)
 	}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf("%s%s", client.getUrl(), client.ToRelativePath(path)), body)
+	// body is streamed, sent with chunked transfer encoding if its size is unknown
+	contentType, body, err := oss.DetectContentType(path, body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Host", client.GetEndpoint())
-	req.Header.Set("Authorization", client.authorization(req))
-	result, err := client.Client.Do(req)
+	size, ok := oss.ReaderSize(body)
+	if !ok {
+		size = -1
+	}
+
+	req, err := client.newRequest(ctx, "PUT", path, "", body)
 	if err != nil {
 		return nil, err
 	}
-	if result.StatusCode != http.StatusOK {
-		d, err := ioutil.ReadAll(ioutil.NopCloser(result.Body))
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(d))
+	if req.ContentLength = size; size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", contentType)
+	client.setACL(req)
+	result, err := client.do(req)
+	if err != nil {
+		return nil, err
 	}
+	result.Body.Close()
 	now := time.Now()
 	return &oss.Object{
 		Path:             path,
@@ -120,58 +244,359 @@ func (client Client) Put(path string, body io.Reader) (*oss.Object, error) {
 	}, nil
 }
 
+func (client Client) Stat(path string) (*oss.ObjectInfo, error) {
+	return client.StatContext(context.Background(), path)
+}
+
+func (client Client) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	req, err := client.newRequest(ctx, "HEAD", path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	result.Body.Close()
+
+	info := &oss.ObjectInfo{
+		Path:         path,
+		Name:         filepath.Base(path),
+		Size:         result.ContentLength,
+		ETag:         strings.Trim(result.Header.Get("ETag"), `"`),
+		ContentType:  result.Header.Get("Content-Type"),
+		CacheControl: result.Header.Get("Cache-Control"),
+		StorageClass: result.Header.Get("x-cos-storage-class"),
+		Metadata:     map[string]string{},
+	}
+	if info.StorageClass == "" {
+		info.StorageClass = "STANDARD"
+	}
+	if lastModified, err := http.ParseTime(result.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = &lastModified
+	}
+	for key := range result.Header {
+		if name := strings.ToLower(key); strings.HasPrefix(name, "x-cos-meta-") {
+			info.Metadata[strings.TrimPrefix(name, "x-cos-meta-")] = result.Header.Get(key)
+		}
+	}
+	return info, nil
+}
+
 func (client Client) Delete(path string) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s%s", client.getUrl(), client.ToRelativePath(path)), nil)
+	return client.DeleteContext(context.Background(), path)
+}
+
+func (client Client) DeleteContext(ctx context.Context, path string) error {
+	req, err := client.newRequest(ctx, "DELETE", path, "", nil)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Host", client.GetEndpoint())
-	req.Header.Set("Authorization", client.authorization(req))
-	result, err := client.Client.Do(req)
+	result, err := client.do(req)
 	if err != nil {
 		return err
 	}
-	if result.StatusCode != http.StatusOK && result.StatusCode != http.StatusNoContent {
-		d, err := ioutil.ReadAll(ioutil.NopCloser(result.Body))
-		if err != nil {
-			return err
-		}
-		return errors.New(string(d))
+	return result.Body.Close()
+}
+
+// DeleteMany delete files
+func (client Client) DeleteMany(paths []string) (oss.BatchResult, error) {
+	return client.DeleteManyContext(context.Background(), paths)
+}
+
+// DeleteManyContext delete files one by one concurrently with DELETE Object
+func (client Client) DeleteManyContext(ctx context.Context, paths []string) (oss.BatchResult, error) {
+	return oss.DeleteEach(ctx, client, paths)
+}
+
+// GetSignedURL generate a pre-signed URL of the file
+func (client Client) GetSignedURL(path string, options oss.URLOptions) (string, error) {
+	return client.GetSignedURLContext(context.Background(), path, options)
+}
+
+// GetSignedURLContext generate a pre-signed URL of the file, the signature is put in the query string
+func (client Client) GetSignedURLContext(ctx context.Context, path string, options oss.URLOptions) (string, error) {
+	query := url.Values{}
+	if options.ResponseContentDisposition != "" {
+		query.Set("response-content-disposition", options.ResponseContentDisposition)
 	}
-	return nil
+	if options.ResponseContentType != "" {
+		query.Set("response-content-type", options.ResponseContentType)
+	}
+
+	req, err := client.newRequest(ctx, options.GetMethod(), path, query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	signature := client.sign(req, getSignTime(options.GetExpires()))
+	if req.URL.RawQuery != "" {
+		req.URL.RawQuery += "&"
+	}
+	req.URL.RawQuery += strings.ReplaceAll(signature, ";", "%3B")
+	return req.URL.String(), nil
+}
+
+// Copy copy file from "from" to "to"
+func (client Client) Copy(from, to string) error {
+	return client.CopyContext(context.Background(), from, to)
+}
+
+// CopyContext copy file from "from" to "to" with COS PUT Object - Copy API
+func (client Client) CopyContext(ctx context.Context, from, to string) error {
+	req, err := client.newRequest(ctx, "PUT", to, "", nil)
+	if err != nil {
+		return err
+	}
+	source := url.URL{Path: client.getHost() + "/" + client.ToRelativePath(from)}
+	req.Header.Set("x-cos-copy-source", source.EscapedPath())
+	client.setACL(req)
+
+	result, err := client.do(req)
+	if err != nil {
+		return err
+	}
+	return result.Body.Close()
+}
+
+// Move move file from "from" to "to", the file is copied then deleted
+func (client Client) Move(from, to string) error {
+	return client.MoveContext(context.Background(), from, to)
+}
+
+// MoveContext move file from "from" to "to"
+func (client Client) MoveContext(ctx context.Context, from, to string) error {
+	if client.ToRelativePath(from) == client.ToRelativePath(to) {
+		return nil
+	}
+	if err := client.CopyContext(ctx, from, to); err != nil {
+		return err
+	}
+	return client.DeleteContext(ctx, from)
 }
 
-//todo not found api
 func (client Client) List(path string) ([]*oss.Object, error) {
-	var objects []*oss.Object
+	return client.ListContext(context.Background(), path)
+}
 
-	results, err := client.Get(path)
+// ListContext list all objects under current path with COS GET Bucket API, all pages are listed by marker
+func (client Client) ListContext(ctx context.Context, path string) ([]*oss.Object, error) {
+	var (
+		objects []*oss.Object
+		options = oss.ListOptions{Recursive: true}
+	)
 
-	if err == nil {
-		objects = append(objects, &oss.Object{
-			Path: client.ToRelativePath(path),
-			Name: results.Name(),
-			//LastModified:     &obj.LastModified,
-			StorageInterface: client,
-		})
+	if path != "" {
+		options.Prefix = strings.Trim(client.ToRelativePath(path), "/") + "/"
+	}
+
+	for {
+		result, err := client.ListPage(ctx, options)
+		if err != nil {
+			return nil, err
+		}
 
+		objects = append(objects, result.Objects...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		options.ContinuationToken = result.NextContinuationToken
 	}
-	return objects, err
 }
 
 func (client Client) GetEndpoint() string {
 	if client.Config.Endpoint != "" {
+		if u, err := url.Parse(client.Config.Endpoint); err == nil && u.Host != "" {
+			return u.Host
+		}
 		return client.Config.Endpoint
 	}
 	return fmt.Sprintf("%s.cos.%s.myqcloud.com", client.Config.Bucket, client.Config.Region)
 }
 
 func (client Client) GetURL(path string) (string, error) {
+	return client.GetURLContext(context.Background(), path)
+}
+
+// GetURLContext get URL of the file, it is a pre-signed URL if the ACL is private
+func (client Client) GetURLContext(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if client.Config.ACL == "private" {
+		return client.GetSignedURLContext(ctx, path, oss.URLOptions{})
+	}
 	return fmt.Sprintf("%s%s", client.getUrl(), client.ToRelativePath(path)), nil
 }
 
+// SetCORS apply Config.CORS to the bucket, so browsers on the origins could access files with GET, PUT, POST, DELETE and HEAD
+func (client Client) SetCORS(ctx context.Context) error {
+	type corsRule struct {
+		AllowedOrigin []string
+		AllowedMethod []string
+		AllowedHeader []string
+		ExposeHeader  []string
+		MaxAgeSeconds int
+	}
+	configuration := struct {
+		XMLName  xml.Name `xml:"CORSConfiguration"`
+		CORSRule corsRule
+	}{CORSRule: corsRule{
+		AllowedMethod: []string{"GET", "PUT", "POST", "DELETE", "HEAD"},
+		AllowedHeader: []string{"*"},
+		ExposeHeader:  []string{"ETag", "Content-Length", "x-cos-request-id"},
+		MaxAgeSeconds: 600,
+	}}
+	for _, origin := range strings.Split(client.Config.CORS, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			configuration.CORSRule.AllowedOrigin = append(configuration.CORSRule.AllowedOrigin, origin)
+		}
+	}
+	if len(configuration.CORSRule.AllowedOrigin) == 0 {
+		return errors.New("tencent: CORS origins are not configured")
+	}
+
+	data, err := xml.Marshal(configuration)
+	if err != nil {
+		return err
+	}
+
+	req, err := client.newRequest(ctx, "PUT", "", "cors", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	checksum := md5.Sum(data)
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(checksum[:]))
+
+	resp, err := client.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// newRequest build a request to the object of path, query is the raw query string
+func (client Client) newRequest(ctx context.Context, method, path, query string, body io.Reader) (*http.Request, error) {
+	reqURL := fmt.Sprintf("%s%s", client.getUrl(), client.ToRelativePath(path))
+	if query != "" {
+		reqURL += "?" + query
+	}
+
+	// bodies are owned by callers, e.g. files, the transport shouldn't close them
+	requestBody := body
+	if _, ok := body.(io.Closer); ok && body != http.NoBody {
+		requestBody = ioutil.NopCloser(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Host", req.URL.Host)
+
+	// seekable bodies are rewound when the request is retried
+	if seeker, ok := body.(io.ReadSeeker); ok && req.GetBody == nil {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				_, err := seeker.Seek(offset, io.SeekStart)
+				return ioutil.NopCloser(seeker), err
+			}
+		}
+	}
+	return req, nil
+}
+
+// setACL set ACL of the object to upload
+func (client Client) setACL(req *http.Request) {
+	if client.Config.ACL != "" {
+		req.Header.Set("x-cos-acl", client.Config.ACL)
+	}
+}
+
+// do sign and send the request, responses with non 2xx status code are returned as error,
+// requests failed with 5xx or 429 are retried with exponential backoff if their bodies could be rewound
+func (client Client) do(req *http.Request) (*http.Response, error) {
+	maxRetries := client.Config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	backoff := 100 * time.Millisecond
+	for retry := 0; ; retry++ {
+		resp, err := client.send(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return resp, nil
+		}
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || retry >= maxRetries || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			defer resp.Body.Close()
+			return nil, responseError(resp)
+		}
+
+		delay := backoff
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		select {
+		case <-time.After(delay):
+			backoff *= 2
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// send sign and send the request once, the timeout applies until the response body is closed
+func (client Client) send(req *http.Request) (*http.Response, error) {
+	req.Header.Del("Authorization")
+	req.Header.Set("Authorization", client.authorization(req))
+
+	if client.Config.Timeout <= 0 {
+		return client.Client.Do(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), client.Config.Timeout)
+	resp, err := client.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelReadCloser cancel the request's context when the body is closed
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelReadCloser) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
+
 func (client Client) authorization(req *http.Request) string {
-	signTime := getSignTime()
+	return client.sign(req, getSignTime(time.Second*1800))
+}
+
+// sign get signature of the request valid in signTime, headers and query parameters of the request are signed
+func (client Client) sign(req *http.Request, signTime string) string {
 	signature := getSignature(client.Config.AccessKey, req, signTime)
 	authStr := fmt.Sprintf("q-sign-algorithm=sha1&q-ak=%s&q-sign-time=%s&q-key-time=%s&q-header-list=%s&q-url-param-list=%s&q-signature=%s",
 		client.Config.AccessID, signTime, signTime, getHeadKeys(req.Header), getParamsKeys(req.URL.RawQuery), signature)
@@ -28,9 +28,9 @@ func hmacSha(k, s string) string {
 	return hex.EncodeToString(b)
 }
 
-func getSignTime() string {
+func getSignTime(expires time.Duration) string {
 	now := time.Now()
-	expired := now.Add(time.Second * 1800)
+	expired := now.Add(expires)
 	return fmt.Sprintf("%d;%d", now.Unix(), expired.Unix())
 }
 
@@ -0,0 +1,67 @@
+// Package fakealiyun an in-process Aliyun OSS compatible server, it implements the subset of OSS API used by the aliyun package,
+// so Aliyun storage could be tested offline, the server's URL is an IP endpoint, so requests are sent in path style:
+//
+//	server := fakealiyun.New()
+//	defer server.Close()
+//
+//	client := aliyun.New(&aliyun.Config{AccessID: "id", AccessKey: "key", Bucket: "bucket", Endpoint: server.URL})
+//
+// Buckets are created on first use, signatures are not verified
+package fakealiyun
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/qor/oss/tests/internal/objectstore"
+)
+
+// Object an object saved in the server
+type Object = objectstore.Object
+
+// Server fake Aliyun OSS server
+type Server struct {
+	*httptest.Server
+	handler *objectstore.Handler
+}
+
+// New start a fake Aliyun OSS server, close it with Close after use
+func New() *Server {
+	server := &Server{handler: objectstore.NewHandler(objectstore.Dialect{
+		Name:         "fakealiyun",
+		HeaderPrefix: "X-Oss-",
+		ACLHeader:    "X-Oss-Object-Acl",
+		CopySource:   parseCopySource,
+	})}
+	server.Server = httptest.NewServer(server.handler)
+	return server
+}
+
+// Object get a saved object, could be used to check ACL, metadata of uploaded objects
+func (server *Server) Object(bucket, key string) (*Object, bool) {
+	return server.handler.Get(bucket, strings.TrimPrefix(key, "/"))
+}
+
+// DenyDelete deny deleting objects matched deny with AccessDenied errors
+func (server *Server) DenyDelete(deny func(bucket, key string) bool) {
+	server.handler.DenyDelete = deny
+}
+
+// IgnoreRange respond to Range requests with the whole object, could be used to test servers don't support ranges
+func (server *Server) IgnoreRange() {
+	server.handler.IgnoreRange = true
+}
+
+// Uploads count in-progress multipart uploads
+func (server *Server) Uploads() int {
+	return server.handler.Uploads()
+}
+
+// parseCopySource parse copy source like "/bucket/key?versionId=id", the key is escaped with url.QueryEscape by the SDK
+func parseCopySource(header string) (string, string, error) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(header, "/"), "/")
+	key, _, _ = strings.Cut(key, "?versionId=")
+	key, err := url.QueryUnescape(key)
+	return bucket, key, err
+}
@@ -0,0 +1,265 @@
+// Package fakecos an in-process Tencent COS compatible server of a single bucket, it implements the subset of COS XML API used by the tencent package,
+// so Tencent storage could be tested offline:
+//
+//	server := fakecos.New("secret_id", "secret_key")
+//	defer server.Close()
+//
+//	client := tencent.New(&tencent.Config{AccessID: "secret_id", AccessKey: "secret_key", Bucket: "bucket", Endpoint: server.URL})
+//
+// Requests are verified with the COS (q-sign-algorithm=sha1) signature in the Authorization header or the query string,
+// anonymous requests could only read objects with public ACL
+package fakecos
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qor/oss/tests/internal/objectstore"
+)
+
+const bucket = "fake-bucket"
+
+// Object an object saved in the server
+type Object = objectstore.Object
+
+// Server fake Tencent COS server
+type Server struct {
+	*httptest.Server
+	SecretID  string
+	SecretKey string
+	// BucketACL ACL of the bucket, used for objects uploaded without ACL, default to private
+	BucketACL string
+
+	handler *objectstore.Handler
+	mutex   sync.Mutex
+	cors    string
+}
+
+// New start a fake Tencent COS server accepting requests signed with the secret ID and key, close it with Close after use
+func New(secretID, secretKey string) *Server {
+	server := &Server{SecretID: secretID, SecretKey: secretKey, BucketACL: "private"}
+	server.handler = objectstore.NewHandler(objectstore.Dialect{
+		Name:         "fakecos",
+		HeaderPrefix: "X-Cos-",
+		ACLHeader:    "X-Cos-Acl",
+		Bucket:       bucket,
+		CopySource:   parseCopySource,
+	})
+	server.Server = httptest.NewServer(server)
+	return server
+}
+
+// Object get a saved object, could be used to check ACL, metadata of uploaded objects
+func (server *Server) Object(key string) (*Object, bool) {
+	return server.handler.Get(bucket, strings.TrimPrefix(key, "/"))
+}
+
+// IgnoreRange respond to Range requests with the whole object, could be used to test servers don't support ranges
+func (server *Server) IgnoreRange() {
+	server.handler.IgnoreRange = true
+}
+
+// Uploads count in-progress multipart uploads
+func (server *Server) Uploads() int {
+	return server.handler.Uploads()
+}
+
+// CORS get the bucket's CORS configuration set with PUT Bucket cors
+func (server *Server) CORS() string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.cors
+}
+
+// ServeHTTP verify the request then handle it
+func (server *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	err := server.authenticate(req)
+	if errors.Is(err, errAnonymous) && server.isPublic(req) {
+		err = nil
+	}
+
+	var authErr *authError
+	if errors.As(err, &authErr) {
+		objectstore.WriteError(w, http.StatusForbidden, authErr.code, authErr.message, "fakecos")
+		return
+	}
+
+	if req.URL.Path == "/" && req.URL.Query().Has("cors") {
+		server.serveCORS(w, req)
+		return
+	}
+	server.handler.ServeHTTP(w, req)
+}
+
+// serveCORS get or put the bucket's CORS configuration, Content-MD5 is required to put it
+func (server *Server) serveCORS(w http.ResponseWriter, req *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	switch req.Method {
+	case http.MethodGet:
+		if server.cors == "" {
+			objectstore.WriteError(w, http.StatusNotFound, "NoSuchCORSConfiguration", "The CORS configuration does not exist.", "fakecos")
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		io.WriteString(w, server.cors)
+	case http.MethodPut:
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			objectstore.WriteError(w, http.StatusBadRequest, "IncompleteBody", err.Error(), "fakecos")
+			return
+		}
+
+		checksum := md5.Sum(content)
+		if req.Header.Get("Content-MD5") != base64.StdEncoding.EncodeToString(checksum[:]) {
+			objectstore.WriteError(w, http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified is not valid.", "fakecos")
+			return
+		}
+		if err := xml.Unmarshal(content, &struct {
+			XMLName xml.Name `xml:"CORSConfiguration"`
+		}{}); err != nil {
+			objectstore.WriteError(w, http.StatusBadRequest, "MalformedXML", err.Error(), "fakecos")
+			return
+		}
+		server.cors = string(content)
+	case http.MethodDelete:
+		server.cors = ""
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		objectstore.WriteError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method is not allowed", "fakecos")
+	}
+}
+
+// isPublic anonymous requests could get objects with public-read or public-read-write ACL
+func (server *Server) isPublic(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	acl := server.BucketACL
+	if obj, ok := server.Object(req.URL.Path); ok && obj.ACL != "" && obj.ACL != "default" {
+		acl = obj.ACL
+	}
+	return acl == "public-read" || acl == "public-read-write"
+}
+
+var errAnonymous = &authError{code: "AccessDenied", message: "Access Denied."}
+
+type authError struct {
+	code, message string
+}
+
+func (err *authError) Error() string {
+	return err.code + ": " + err.message
+}
+
+// authenticate verify signature of the request, see https://cloud.tencent.com/document/product/436/7778
+func (server *Server) authenticate(req *http.Request) error {
+	var (
+		query  = req.URL.Query()
+		params = map[string]string{}
+	)
+
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		for _, pair := range strings.Split(auth, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			params[key] = value
+		}
+	} else if query.Has("q-signature") {
+		for key := range query {
+			if strings.HasPrefix(key, "q-") {
+				params[key] = query.Get(key)
+			}
+		}
+	} else {
+		return errAnonymous
+	}
+
+	if params["q-sign-algorithm"] != "sha1" {
+		return &authError{"InvalidArgument", "q-sign-algorithm should be sha1"}
+	}
+	if params["q-ak"] != server.SecretID {
+		return &authError{"InvalidAccessKeyId", "The access key Id format you provided is invalid."}
+	}
+
+	var start, end int64
+	if _, err := fmt.Sscanf(params["q-sign-time"], "%d;%d", &start, &end); err != nil {
+		return &authError{"InvalidArgument", "q-sign-time is invalid"}
+	}
+	if now := time.Now().Unix(); now < start-60 || now > end {
+		return &authError{"AccessDenied", "Request has expired"}
+	}
+
+	var headers, urlParams []string
+	for _, key := range splitList(params["q-header-list"]) {
+		value := req.Header.Get(key)
+		if key == "host" {
+			value = req.Host
+		}
+		headers = append(headers, key+"="+escape(value))
+	}
+	for _, key := range splitList(params["q-url-param-list"]) {
+		for name, values := range query {
+			if strings.ToLower(name) == key {
+				urlParams = append(urlParams, key+"="+escape(values[0]))
+			}
+		}
+	}
+
+	httpString := fmt.Sprintf("%s\n%s\n%s\n%s\n", strings.ToLower(req.Method), req.URL.Path, strings.Join(urlParams, "&"), strings.Join(headers, "&"))
+	stringToSign := fmt.Sprintf("sha1\n%s\n%s\n", params["q-sign-time"], sha1Hex(httpString))
+	signature := hmacSha1(hmacSha1(server.SecretKey, params["q-key-time"]), stringToSign)
+
+	if !hmac.Equal([]byte(signature), []byte(params["q-signature"])) {
+		return &authError{"SignatureDoesNotMatch", "The calculated signature does not match the signature you provided."}
+	}
+	return nil
+}
+
+// parseCopySource parse copy source like "bucket-appid.cos.region.myqcloud.com/key", objects could only be copied in the bucket
+func parseCopySource(header string) (string, string, error) {
+	_, key, _ := strings.Cut(header, "/")
+	key, err := url.PathUnescape(key)
+	return bucket, key, err
+}
+
+// splitList split and sort lowercased keys of q-header-list or q-url-param-list
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	keys := strings.Split(strings.ToLower(list), ";")
+	sort.Strings(keys)
+	return keys
+}
+
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha1(key, s string) string {
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
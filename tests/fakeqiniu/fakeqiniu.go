@@ -0,0 +1,476 @@
+// Package fakeqiniu an in-process Qiniu compatible server of a single bucket, it implements the subset of Qiniu APIs used by the qiniu package:
+// form upload, resumable upload (v2), resource management (rs), listing (rsf) and downloading from the bucket's domain,
+// so Qiniu storage could be tested offline:
+//
+//	server := fakeqiniu.New("access_key", "secret_key", "bucket")
+//	defer server.Close()
+//
+//	client := qiniu.New(&qiniu.Config{AccessID: "access_key", AccessKey: "secret_key", Bucket: "bucket", Zone: server.Zone(), Endpoint: server.Endpoint()})
+//
+// Upload tokens, management tokens and download tokens of private buckets are verified, put policies of upload tokens are enforced,
+// including insertOnly, fsizeMin, fsizeLimit, mimeLimit, detectMime, returnBody and callbacks
+package fakeqiniu
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/api.v7/v7/auth"
+	"github.com/qiniu/api.v7/v7/storage"
+	"github.com/qor/oss/tests/internal/objectstore"
+)
+
+// Qiniu's status codes of errors
+const (
+	statusBadToken     = http.StatusUnauthorized
+	statusNoSuchFile   = 612
+	statusFileExists   = 614
+	statusNoSuchBucket = 631
+	statusCallback     = 579
+)
+
+// Object an object saved in the server, ETag is Qiniu's hash of the content
+type Object = objectstore.Object
+
+// Server fake Qiniu server, the embedded server serves upload and management APIs, the download server serves files of the bucket
+type Server struct {
+	*httptest.Server
+	Download  *httptest.Server
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	// Private files of private buckets could only be downloaded with download tokens
+	Private bool
+	// DenyDelete deny deleting matched files, could be used to test failures of batch deletes
+	DenyDelete func(key string) bool
+
+	store    *objectstore.Store
+	mutex    sync.Mutex
+	policies map[string]storage.PutPolicy
+}
+
+// New start a fake Qiniu server of bucket accepting tokens signed with the keys, close it with Close after use
+func New(accessKey, secretKey, bucket string) *Server {
+	server := &Server{AccessKey: accessKey, SecretKey: secretKey, Bucket: bucket, store: objectstore.New(), policies: map[string]storage.PutPolicy{}}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveAPI))
+	server.Download = httptest.NewServer(http.HandlerFunc(server.serveDownload))
+	return server
+}
+
+// Close shutdown the API and download servers
+func (server *Server) Close() {
+	server.Server.Close()
+	server.Download.Close()
+}
+
+// Zone zone whose upload, rs and rsf hosts are the server
+func (server *Server) Zone() *storage.Zone {
+	host := server.Listener.Addr().String()
+	return &storage.Zone{
+		SrcUpHosts: []string{host},
+		CdnUpHosts: []string{host},
+		RsHost:     host,
+		RsfHost:    host,
+		ApiHost:    host,
+		IovipHost:  host,
+	}
+}
+
+// Endpoint URL of the download server, used as the bucket's domain
+func (server *Server) Endpoint() string {
+	return server.Download.URL
+}
+
+// Object get a saved object, could be used to check content type, hash of uploaded objects
+func (server *Server) Object(key string) (*Object, bool) {
+	return server.store.Get(server.Bucket, strings.TrimPrefix(key, "/"))
+}
+
+// PutPolicy get put policy of the upload token used to save the key last time, could be used to check scope, expiry etc of upload tokens
+func (server *Server) PutPolicy(key string) (storage.PutPolicy, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	policy, ok := server.policies[strings.TrimPrefix(key, "/")]
+	return policy, ok
+}
+
+// Uploads count in-progress resumable uploads
+func (server *Server) Uploads() int {
+	return server.store.Uploads()
+}
+
+func (server *Server) serveAPI(w http.ResponseWriter, req *http.Request) {
+	switch parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/"); {
+	case req.URL.Path == "/" && req.Method == http.MethodPost:
+		server.formUpload(w, req)
+	case parts[0] == "buckets":
+		server.resumableUpload(w, req, parts[1:])
+	case parts[0] == "list":
+		if server.authorize(w, req) {
+			server.list(w, req)
+		}
+	case parts[0] == "batch":
+		if server.authorize(w, req) {
+			server.batch(w, req)
+		}
+	case parts[0] == "stat" || parts[0] == "delete" || parts[0] == "copy" || parts[0] == "move":
+		if server.authorize(w, req) {
+			server.manage(w, parts)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "no such api")
+	}
+}
+
+// authorize verify management token of the request, signed as "QBox" or "Qiniu" token
+func (server *Server) authorize(w http.ResponseWriter, req *http.Request) bool {
+	var (
+		credentials   = auth.New(server.AccessKey, server.SecretKey)
+		authorization = req.Header.Get("Authorization")
+		token         string
+		err           error
+	)
+
+	switch {
+	case strings.HasPrefix(authorization, "Qiniu "):
+		token, err = credentials.SignRequestV2(req)
+		token = "Qiniu " + token
+	case strings.HasPrefix(authorization, "QBox "):
+		token, err = credentials.SignRequest(req)
+		token = "QBox " + token
+	}
+
+	if err != nil || token == "" || !hmac.Equal([]byte(token), []byte(authorization)) {
+		writeError(w, statusBadToken, "bad token")
+		return false
+	}
+	return true
+}
+
+// putPolicy verify the upload token and return its put policy
+func (server *Server) putPolicy(token string) (*storage.PutPolicy, error) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 3 || parts[0] != server.AccessKey || !hmac.Equal([]byte(parts[1]), []byte(server.sign(parts[2]))) {
+		return nil, errors.New("bad token")
+	}
+
+	content, err := base64.URLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("bad token")
+	}
+
+	var policy storage.PutPolicy
+	if err := json.Unmarshal(content, &policy); err != nil {
+		return nil, errors.New("bad token")
+	}
+	if policy.Expires < uint64(time.Now().Unix()) {
+		return nil, errors.New("token out of date")
+	}
+	return &policy, nil
+}
+
+// allowPut check if the put policy allows saving key, files could only be overwritten if the scope is "bucket:key" and it isn't insert only,
+// size and mime type are not checked if they are unknown yet (negative size and blank mime type)
+func (server *Server) allowPut(w http.ResponseWriter, policy *storage.PutPolicy, key string, size int64, mimeType string) bool {
+	bucket, scopeKey, hasKey := strings.Cut(policy.Scope, ":")
+	switch {
+	case bucket != server.Bucket:
+		writeError(w, statusNoSuchBucket, "no such bucket")
+	case hasKey && policy.IsPrefixalScope == 0 && scopeKey != key, hasKey && policy.IsPrefixalScope != 0 && !strings.HasPrefix(key, scopeKey):
+		writeError(w, http.StatusForbidden, "key doesn't match with scope")
+	case policy.FsizeLimit > 0 && size > policy.FsizeLimit:
+		writeError(w, http.StatusRequestEntityTooLarge, "file is too large")
+	case policy.FsizeMin > 0 && size >= 0 && size < policy.FsizeMin:
+		writeError(w, http.StatusForbidden, "file is too small")
+	case policy.MimeLimit != "" && mimeType != "" && !matchMimeLimit(policy.MimeLimit, mimeType):
+		writeError(w, http.StatusForbidden, "file type is not allowed")
+	default:
+		if _, exists := server.Object(key); exists && (!hasKey || policy.IsPrefixalScope != 0 || policy.InsertOnly != 0) {
+			writeError(w, statusFileExists, "file exists")
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// save save content as key, Qiniu's hash of the content is used as ETag
+func (server *Server) save(obj *Object, content []byte) {
+	obj.SetContent(content)
+	obj.ETag = Hash(content)
+	if obj.ContentType == "" {
+		obj.ContentType = "application/octet-stream"
+	}
+	server.store.Put(server.Bucket, obj)
+}
+
+// manage handle rs APIs: /stat/{entry}, /delete/{entry}, /copy/{from}/{to}[/force/{bool}] and /move/{from}/{to}[/force/{bool}]
+func (server *Server) manage(w http.ResponseWriter, parts []string) {
+	status, ret := server.execute(parts)
+	writeJSON(w, status, ret)
+}
+
+// batch handle /batch API, operations are sent as "op" form values like "/delete/{entry}", the status is 298 if some operations failed
+func (server *Server) batch(w http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	operations := req.PostForm["op"]
+	if len(operations) > 1000 {
+		writeError(w, http.StatusBadRequest, "too many operations")
+		return
+	}
+
+	type opRet struct {
+		Code int         `json:"code"`
+		Data interface{} `json:"data,omitempty"`
+	}
+	var (
+		status = http.StatusOK
+		rets   = []opRet{}
+	)
+	for _, operation := range operations {
+		code, data := http.StatusBadRequest, interface{}(errorBody("invalid operation"))
+		switch parts := strings.Split(strings.Trim(operation, "/"), "/"); parts[0] {
+		case "stat", "delete", "copy", "move":
+			code, data = server.execute(parts)
+		}
+
+		if code != http.StatusOK {
+			status = 298
+		}
+		rets = append(rets, opRet{Code: code, Data: data})
+	}
+	writeJSON(w, status, rets)
+}
+
+// execute execute a rs operation, returns status code and response
+func (server *Server) execute(parts []string) (int, interface{}) {
+	count := 1
+	if parts[0] == "copy" || parts[0] == "move" {
+		count = 2
+	}
+	if len(parts) < 1+count {
+		return http.StatusBadRequest, errorBody("invalid entry")
+	}
+
+	var keys []string
+	for _, encoded := range parts[1 : 1+count] {
+		entry, err := base64.URLEncoding.DecodeString(encoded)
+		if err != nil {
+			return http.StatusBadRequest, errorBody("invalid entry")
+		}
+
+		bucket, key, _ := strings.Cut(string(entry), ":")
+		if bucket != server.Bucket {
+			return statusNoSuchBucket, errorBody("no such bucket")
+		}
+		keys = append(keys, key)
+	}
+
+	obj, ok := server.Object(keys[0])
+	if !ok {
+		return statusNoSuchFile, errorBody("no such file or directory")
+	}
+
+	switch parts[0] {
+	case "stat":
+		return http.StatusOK, storage.FileInfo{
+			Hash:     obj.ETag,
+			Fsize:    int64(len(obj.Content)),
+			PutTime:  obj.LastModified.UnixNano() / 100,
+			MimeType: obj.ContentType,
+		}
+	case "delete":
+		if server.DenyDelete != nil && server.DenyDelete(keys[0]) {
+			return http.StatusForbidden, errorBody("permission denied")
+		}
+		server.store.Delete(server.Bucket, keys[0])
+		return http.StatusOK, nil
+	case "copy", "move":
+		force := len(parts) >= 5 && parts[3] == "force" && parts[4] == "true"
+		if _, exists := server.Object(keys[1]); exists && !force {
+			return statusFileExists, errorBody("file exists")
+		}
+
+		copied := *obj
+		copied.Key, copied.LastModified = keys[1], time.Now()
+		server.store.Put(server.Bucket, &copied)
+		if parts[0] == "move" && keys[0] != keys[1] {
+			server.store.Delete(server.Bucket, keys[0])
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// list handle rsf list API, the marker is the encoded last key or common prefix of the page
+func (server *Server) list(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	if query.Get("bucket") != server.Bucket {
+		writeError(w, statusNoSuchBucket, "no such bucket")
+		return
+	}
+
+	options := objectstore.ListOptions{Prefix: query.Get("prefix"), Delimiter: query.Get("delimiter"), MaxKeys: 1000}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit < options.MaxKeys {
+		options.MaxKeys = limit
+	}
+	if marker := query.Get("marker"); marker != "" {
+		decoded, err := base64.URLEncoding.DecodeString(marker)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid marker")
+			return
+		}
+		options.Marker = string(decoded)
+	}
+
+	listed := server.store.List(server.Bucket, options)
+	ret := struct {
+		Marker         string             `json:"marker,omitempty"`
+		CommonPrefixes []string           `json:"commonPrefixes,omitempty"`
+		Items          []storage.ListItem `json:"items"`
+	}{CommonPrefixes: listed.CommonPrefixes, Items: []storage.ListItem{}}
+	if listed.IsTruncated {
+		ret.Marker = base64.URLEncoding.EncodeToString([]byte(listed.NextMarker))
+	}
+	for _, obj := range listed.Objects {
+		ret.Items = append(ret.Items, storage.ListItem{
+			Key:      obj.Key,
+			Hash:     obj.ETag,
+			Fsize:    int64(len(obj.Content)),
+			PutTime:  obj.LastModified.UnixNano() / 100,
+			MimeType: obj.ContentType,
+		})
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
+
+// serveDownload serve files of the bucket, download tokens are required for private buckets
+func (server *Server) serveDownload(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := req.URL.Query()
+	if server.Private {
+		// the token signs the URL before it
+		urlToSign, token, _ := strings.Cut("http://"+req.Host+req.RequestURI, "&token=")
+		expires, _ := strconv.ParseInt(query.Get("e"), 10, 64)
+		if token == "" || !hmac.Equal([]byte(token), []byte(server.AccessKey+":"+server.sign(urlToSign))) {
+			writeError(w, statusBadToken, "bad token")
+			return
+		} else if expires < time.Now().Unix() {
+			writeError(w, statusBadToken, "token out of date")
+			return
+		}
+	}
+
+	obj, ok := server.Object(req.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	header := w.Header()
+	header.Set("ETag", `"`+obj.ETag+`"`)
+	header.Set("Content-Type", obj.ContentType)
+	header.Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	header.Set("Accept-Ranges", "bytes")
+	if name := query.Get("attname"); name != "" {
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+
+	content, status := obj.Content, http.StatusOK
+	if rangeHeader := req.Header.Get("Range"); rangeHeader != "" {
+		start, end, ok := objectstore.ParseRange(rangeHeader, int64(len(content)))
+		if !ok {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", len(content)))
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "invalid range")
+			return
+		}
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		content, status = content[start:end+1], http.StatusPartialContent
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(status)
+	if req.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+// sign sign data with the secret key like Qiniu's tokens
+func (server *Server) sign(data string) string {
+	mac := hmac.New(sha1.New, []byte(server.SecretKey))
+	mac.Write([]byte(data))
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Hash Qiniu's hash (etag) of the content, which is the SHA1 of 4MB blocks
+func Hash(content []byte) string {
+	const blockSize = 4 << 20
+
+	var (
+		prefix = byte(0x16)
+		sum    []byte
+	)
+	if len(content) <= blockSize {
+		checksum := sha1.Sum(content)
+		sum = checksum[:]
+	} else {
+		prefix = 0x96
+		blocks := sha1.New()
+		for offset := 0; offset < len(content); offset += blockSize {
+			checksum := sha1.Sum(content[offset:min(offset+blockSize, len(content))])
+			blocks.Write(checksum[:])
+		}
+		sum = blocks.Sum(nil)
+	}
+	return base64.URLEncoding.EncodeToString(append([]byte{prefix}, sum...))
+}
+
+// matchMimeLimit check mime type with mimeLimit of put policy, like "image/*;text/plain" or "!application/json;text/*"
+func matchMimeLimit(mimeLimit, mimeType string) bool {
+	patterns, deny := strings.CutPrefix(mimeLimit, "!")
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	for _, pattern := range strings.Split(patterns, ";") {
+		if matched, _ := path.Match(strings.TrimSpace(pattern), strings.TrimSpace(mimeType)); matched {
+			return !deny
+		}
+	}
+	return deny
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if value != nil {
+		json.NewEncoder(w).Encode(value)
+	} else {
+		w.Write([]byte("{}"))
+	}
+}
+
+// writeError write Qiniu's error response like {"error":"no such file or directory"}
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorBody(message))
+}
+
+func errorBody(message string) map[string]string {
+	return map[string]string{"error": message}
+}
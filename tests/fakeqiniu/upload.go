@@ -0,0 +1,313 @@
+package fakeqiniu
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qiniu/api.v7/v7/auth"
+	"github.com/qiniu/api.v7/v7/storage"
+	"github.com/qor/oss/tests/internal/objectstore"
+)
+
+// formUpload handle form upload, the file is sent as multipart form with the upload token, key and crc32 of the file
+func (server *Server) formUpload(w http.ResponseWriter, req *http.Request) {
+	if err := req.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policy, err := server.putPolicy(req.FormValue("token"))
+	if err != nil {
+		writeError(w, statusBadToken, err.Error())
+		return
+	}
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if value := req.FormValue("crc32"); value != "" {
+		if checksum, err := strconv.ParseUint(value, 10, 32); err != nil || uint32(checksum) != crc32.ChecksumIEEE(content) {
+			writeError(w, http.StatusNotAcceptable, "crc32 not match")
+			return
+		}
+	}
+
+	// files are saved as their hash if key isn't specified
+	key := req.FormValue("key")
+	if _, ok := req.MultipartForm.Value["key"]; !ok {
+		key = Hash(content)
+	}
+
+	obj := &Object{Key: key, ContentType: header.Header.Get("Content-Type")}
+	if policy.DetectMime != 0 {
+		obj.ContentType = http.DetectContentType(content)
+	}
+
+	if !server.allowPut(w, policy, key, int64(len(content)), obj.ContentType) {
+		return
+	}
+
+	server.save(obj, content)
+	server.respond(w, policy, obj, header.Filename)
+}
+
+// resumableUpload handle resumable upload (v2) APIs under /buckets/{bucket}/objects/{encoded key}/uploads
+func (server *Server) resumableUpload(w http.ResponseWriter, req *http.Request, parts []string) {
+	policy, err := server.putPolicy(strings.TrimPrefix(req.Header.Get("Authorization"), "UpToken "))
+	if err != nil {
+		writeError(w, statusBadToken, err.Error())
+		return
+	}
+
+	if len(parts) < 4 || len(parts) > 6 || parts[1] != "objects" || parts[3] != "uploads" {
+		writeError(w, http.StatusNotFound, "no such api")
+		return
+	} else if parts[0] != server.Bucket {
+		writeError(w, statusNoSuchBucket, "no such bucket")
+		return
+	}
+
+	// files without key ("~") are not supported
+	encodedKey, err := base64.URLEncoding.DecodeString(parts[2])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid key")
+		return
+	}
+	key := string(encodedKey)
+
+	if len(parts) == 4 {
+		if req.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if server.allowPut(w, policy, key, -1, "") {
+			id := server.store.CreateUpload(server.Bucket, &Object{Key: key})
+			writeJSON(w, http.StatusOK, map[string]interface{}{"uploadId": id, "expireAt": time.Now().Add(7 * 24 * time.Hour).Unix()})
+		}
+		return
+	}
+
+	id := parts[4]
+	upload, ok := server.store.Upload(id)
+	if !ok || upload.Object.Key != key {
+		writeError(w, statusNoSuchFile, "no such uploadId")
+		return
+	}
+
+	switch {
+	case len(parts) == 6 && req.Method == http.MethodPut:
+		partNumber, err := strconv.Atoi(parts[5])
+		if err != nil || partNumber < 1 || partNumber > 10000 {
+			writeError(w, http.StatusBadRequest, "invalid part number")
+			return
+		}
+
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		part, err := server.store.UploadPart(id, partNumber, content)
+		if err != nil {
+			writeError(w, statusNoSuchFile, "no such uploadId")
+			return
+		}
+		if checksum := req.Header.Get("Content-MD5"); checksum != "" && checksum != part.ETag {
+			writeError(w, http.StatusNotAcceptable, "md5 not match")
+			return
+		}
+		writeJSON(w, http.StatusOK, storage.UploadPartsRet{Etag: part.ETag, MD5: part.ETag})
+	case len(parts) == 5 && req.Method == http.MethodGet:
+		server.listParts(w, req, upload)
+	case len(parts) == 5 && req.Method == http.MethodDelete:
+		server.store.AbortUpload(id)
+		writeJSON(w, http.StatusOK, nil)
+	case len(parts) == 5 && req.Method == http.MethodPost:
+		server.completeUpload(w, req, policy, upload)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (server *Server) listParts(w http.ResponseWriter, req *http.Request, upload objectstore.Upload) {
+	maxParts, err := strconv.Atoi(req.URL.Query().Get("max-parts"))
+	if err != nil || maxParts <= 0 || maxParts > 1000 {
+		maxParts = 1000
+	}
+	marker, _ := strconv.Atoi(req.URL.Query().Get("part-number-marker"))
+
+	type partInfo struct {
+		Size       int64  `json:"size"`
+		Etag       string `json:"etag"`
+		PartNumber int    `json:"partNumber"`
+		PutTime    int64  `json:"putTime"`
+	}
+	ret := struct {
+		UploadID         string     `json:"uploadId"`
+		PartNumberMarker int        `json:"partNumberMarker"`
+		Parts            []partInfo `json:"parts"`
+	}{UploadID: upload.ID, Parts: []partInfo{}}
+
+	parts, _ := server.store.ListParts(upload.ID)
+	for _, part := range parts {
+		if part.Number <= marker {
+			continue
+		}
+		if len(ret.Parts) == maxParts {
+			ret.PartNumberMarker = ret.Parts[len(ret.Parts)-1].PartNumber
+			break
+		}
+
+		obj := upload.Parts[part.Number]
+		ret.Parts = append(ret.Parts, partInfo{Size: int64(len(obj.Content)), Etag: part.ETag, PartNumber: part.Number, PutTime: obj.LastModified.Unix()})
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
+
+func (server *Server) completeUpload(w http.ResponseWriter, req *http.Request, policy *storage.PutPolicy, upload objectstore.Upload) {
+	var body struct {
+		Parts []struct {
+			Etag       string `json:"etag"`
+			PartNumber int    `json:"partNumber"`
+		} `json:"parts"`
+		Fname    string `json:"fname"`
+		MimeType string `json:"mimeType"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var (
+		parts   []objectstore.Part
+		size    int64
+		content []byte
+	)
+	for _, part := range body.Parts {
+		parts = append(parts, objectstore.Part{Number: part.PartNumber, ETag: part.Etag})
+		if obj, ok := upload.Parts[part.PartNumber]; ok {
+			size += int64(len(obj.Content))
+			content = append(content, obj.Content...)
+		}
+	}
+
+	mimeType := body.MimeType
+	if policy.DetectMime != 0 {
+		mimeType = http.DetectContentType(content)
+	}
+
+	if !server.allowPut(w, policy, upload.Object.Key, size, mimeType) {
+		return
+	}
+
+	obj, err := server.store.CompleteUpload(upload.ID, parts)
+	if errors.Is(err, objectstore.ErrInvalidPart) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, statusNoSuchFile, "no such uploadId")
+		return
+	}
+
+	saved := &Object{Key: obj.Key, ContentType: mimeType}
+	server.save(saved, obj.Content)
+	server.respond(w, policy, saved, body.Fname)
+}
+
+// respond write response of the saved file, which is the response of the callback if callbackUrl of the put policy is set,
+// or the returnBody of the put policy, or the hash and key of the file
+func (server *Server) respond(w http.ResponseWriter, policy *storage.PutPolicy, obj *Object, fname string) {
+	server.mutex.Lock()
+	server.policies[obj.Key] = *policy
+	server.mutex.Unlock()
+
+	var persistentID string
+	if policy.PersistentOps != "" {
+		persistentID = "z0.fake." + obj.ETag
+	}
+
+	variables := strings.NewReplacer(
+		"$(bucket)", server.Bucket,
+		"$(key)", obj.Key,
+		"$(etag)", obj.ETag,
+		"$(hash)", obj.ETag,
+		"$(fname)", fname,
+		"$(fsize)", strconv.Itoa(len(obj.Content)),
+		"$(mimeType)", obj.ContentType,
+		"$(persistentId)", persistentID,
+	)
+
+	switch {
+	case policy.CallbackURL != "":
+		server.callback(w, policy, variables.Replace(policy.CallbackBody))
+	case policy.ReturnBody != "":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(variables.Replace(policy.ReturnBody)))
+	default:
+		writeJSON(w, http.StatusOK, storage.PutRet{Hash: obj.ETag, Key: obj.Key, PersistentID: persistentID})
+	}
+}
+
+// callback post body to the first callbackUrl of the put policy with a "QBox" token, and write the callback's response,
+// the callback should respond with JSON
+func (server *Server) callback(w http.ResponseWriter, policy *storage.PutPolicy, body string) {
+	contentType := policy.CallbackBodyType
+	if contentType == "" {
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	callbackURL, _, _ := strings.Cut(policy.CallbackURL, ";")
+	req, err := http.NewRequest(http.MethodPost, callbackURL, strings.NewReader(body))
+	if err != nil {
+		writeError(w, statusCallback, err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", contentType)
+	if req.URL.Path == "" {
+		req.URL.Path = "/" // signed path should be the same as the path received by the callback
+	}
+	if policy.CallbackHost != "" {
+		req.Host = policy.CallbackHost
+	}
+
+	token, err := auth.New(server.AccessKey, server.SecretKey).SignRequest(req)
+	if err != nil {
+		writeError(w, statusCallback, err.Error())
+		return
+	}
+	req.Header.Set("Authorization", "QBox "+token)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		writeError(w, statusCallback, err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil || res.StatusCode != http.StatusOK {
+		writeError(w, statusCallback, fmt.Sprintf("callback failed: %v %s", res.Status, content))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(content)
+}
@@ -0,0 +1,75 @@
+// Package fakes3 an in-process S3 compatible server, it implements the subset of S3 REST API used by the s3 package,
+// so S3 storage could be tested offline, requests are routed to the server with its endpoint resolver:
+//
+//	server := fakes3.New()
+//	defer server.Close()
+//
+//	client := s3.New(&s3.Config{AccessID: "id", AccessKey: "key", Region: "us-east-1", Bucket: "bucket", CustomEndpointResolver: server.EndpointResolver()})
+//
+// Path style requests are also supported by setting S3Endpoint to the server's URL, keys of path style clients start with /.
+// Buckets are created on first use, signatures are not verified
+package fakes3
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyendpoints "github.com/aws/smithy-go/endpoints"
+	"github.com/qor/oss/tests/internal/objectstore"
+)
+
+// Object an object saved in the server
+type Object = objectstore.Object
+
+// Server fake S3 server
+type Server struct {
+	*httptest.Server
+	handler *objectstore.Handler
+}
+
+// New start a fake S3 server, close it with Close after use
+func New() *Server {
+	server := &Server{handler: objectstore.NewHandler(objectstore.Dialect{Name: "fakes3", HeaderPrefix: "X-Amz-", ACLHeader: "X-Amz-Acl"})}
+	server.Server = httptest.NewServer(server.handler)
+	return server
+}
+
+// EndpointResolver resolve endpoints of buckets to the server, so clients could be used without path style
+func (server *Server) EndpointResolver() s3.EndpointResolverV2 {
+	return endpointResolver{server: server}
+}
+
+type endpointResolver struct {
+	server *Server
+}
+
+func (resolver endpointResolver) ResolveEndpoint(ctx context.Context, params s3.EndpointParameters) (smithyendpoints.Endpoint, error) {
+	u, err := url.Parse(resolver.server.URL + "/" + aws.ToString(params.Bucket))
+	if err != nil {
+		return smithyendpoints.Endpoint{}, err
+	}
+	return smithyendpoints.Endpoint{URI: *u}, nil
+}
+
+// Object get a saved object with its S3 key, could be used to check ACL, metadata of uploaded objects
+func (server *Server) Object(bucket, key string) (*Object, bool) {
+	return server.handler.Get(bucket, key)
+}
+
+// DenyDelete deny deleting objects matched deny with AccessDenied errors
+func (server *Server) DenyDelete(deny func(bucket, key string) bool) {
+	server.handler.DenyDelete = deny
+}
+
+// IgnoreRange respond to Range requests with the whole object, could be used to test servers don't support ranges
+func (server *Server) IgnoreRange() {
+	server.handler.IgnoreRange = true
+}
+
+// Uploads count in-progress multipart uploads
+func (server *Server) Uploads() int {
+	return server.handler.Uploads()
+}
@@ -0,0 +1,313 @@
+// Package objectstore objects and multipart uploads saved by fake servers of cloud storages, and a handler of S3 compatible XML APIs
+package objectstore
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoSuchUpload the multipart upload doesn't exist
+	ErrNoSuchUpload = errors.New("objectstore: no such upload")
+	// ErrInvalidPart completed parts are missing, mismatched or not in order
+	ErrInvalidPart = errors.New("objectstore: invalid part")
+)
+
+// Object an object saved in the store, objects are not modified after saved
+type Object struct {
+	Key          string
+	Content      []byte
+	ETag         string
+	ContentType  string
+	CacheControl string
+	ACL          string
+	Metadata     map[string]string
+	LastModified time.Time
+}
+
+// SetContent set content of the object, ETag is the hex MD5 of the content
+func (obj *Object) SetContent(content []byte) {
+	checksum := md5.Sum(content)
+	obj.Content = content
+	obj.ETag = hex.EncodeToString(checksum[:])
+	obj.LastModified = time.Now()
+}
+
+// Upload a multipart upload
+type Upload struct {
+	ID     string
+	Bucket string
+	// Object object to save when completed, with content type, ACL etc set when initiated
+	Object Object
+	Parts  map[int]*Object
+}
+
+// Part an uploaded part
+type Part struct {
+	Number int
+	ETag   string
+}
+
+// Store objects of buckets, buckets are created on first use, it is safe for concurrent use
+type Store struct {
+	mutex   sync.Mutex
+	buckets map[string]map[string]*Object
+	uploads map[string]*Upload
+	nextID  int
+}
+
+// New initialize a store
+func New() *Store {
+	return &Store{buckets: map[string]map[string]*Object{}, uploads: map[string]*Upload{}}
+}
+
+func (store *Store) bucket(name string) map[string]*Object {
+	if _, ok := store.buckets[name]; !ok {
+		store.buckets[name] = map[string]*Object{}
+	}
+	return store.buckets[name]
+}
+
+// Get get object of key
+func (store *Store) Get(bucket, key string) (*Object, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	obj, ok := store.bucket(bucket)[key]
+	return obj, ok
+}
+
+// Put save object, existing object is replaced
+func (store *Store) Put(bucket string, obj *Object) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.bucket(bucket)[obj.Key] = obj
+}
+
+// Delete delete object of key, returns false if it doesn't exist
+func (store *Store) Delete(bucket, key string) bool {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	_, ok := store.bucket(bucket)[key]
+	delete(store.bucket(bucket), key)
+	return ok
+}
+
+// ListOptions options to list objects
+type ListOptions struct {
+	Prefix    string
+	Delimiter string
+	// Marker list objects and common prefixes after it
+	Marker  string
+	MaxKeys int
+}
+
+// ListResult a page of listed objects
+type ListResult struct {
+	Objects        []*Object
+	CommonPrefixes []string
+	IsTruncated    bool
+	// NextMarker marker of next page, which is the last object's key or common prefix of this page
+	NextMarker string
+}
+
+// List list a page of objects in lexicographical order, objects are grouped into common prefixes by the delimiter
+func (store *Store) List(bucket string, options ListOptions) ListResult {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var (
+		keys    []string
+		objects = store.bucket(bucket)
+		marker  = options.Marker
+		result  ListResult
+	)
+	for key := range objects {
+		if strings.HasPrefix(key, options.Prefix) && key > marker {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	count := 0
+	for _, key := range keys {
+		entry := key
+		if options.Delimiter != "" {
+			if i := strings.Index(key[len(options.Prefix):], options.Delimiter); i >= 0 {
+				entry = key[:len(options.Prefix)+i+len(options.Delimiter)]
+				if entry <= marker {
+					continue // the common prefix is returned already
+				}
+			}
+		}
+
+		if count >= options.MaxKeys {
+			result.IsTruncated = true
+			result.NextMarker = marker
+			break
+		}
+
+		if entry != key {
+			result.CommonPrefixes = append(result.CommonPrefixes, entry)
+		} else {
+			result.Objects = append(result.Objects, objects[key])
+		}
+		marker = entry
+		count++
+	}
+	return result
+}
+
+// CreateUpload initiate a multipart upload of obj, returns the upload ID
+func (store *Store) CreateUpload(bucket string, obj *Object) string {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.nextID++
+	id := fmt.Sprintf("upload-%d", store.nextID)
+	store.uploads[id] = &Upload{ID: id, Bucket: bucket, Object: *obj, Parts: map[int]*Object{}}
+	return id
+}
+
+// Upload get a copy of the multipart upload of id
+func (store *Store) Upload(id string) (Upload, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	upload, ok := store.uploads[id]
+	if !ok {
+		return Upload{}, false
+	}
+
+	copied := *upload
+	copied.Parts = map[int]*Object{}
+	for number, part := range upload.Parts {
+		copied.Parts[number] = part
+	}
+	return copied, true
+}
+
+// Uploads count in-progress multipart uploads
+func (store *Store) Uploads() int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return len(store.uploads)
+}
+
+// UploadPart save a part of the multipart upload, existing part is replaced
+func (store *Store) UploadPart(id string, number int, content []byte) (*Object, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	upload, ok := store.uploads[id]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+
+	part := &Object{}
+	part.SetContent(content)
+	upload.Parts[number] = part
+	return part, nil
+}
+
+// ListParts list uploaded parts of the multipart upload sorted by part number
+func (store *Store) ListParts(id string) ([]Part, error) {
+	upload, ok := store.Upload(id)
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+
+	var parts []Part
+	for number, part := range upload.Parts {
+		parts = append(parts, Part{Number: number, ETag: part.ETag})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+// CompleteUpload join parts into the object and save it, parts should be in ascending order,
+// ETag of the object is the MD5 of parts' MD5 with the count of parts like S3
+func (store *Store) CompleteUpload(id string, parts []Part) (*Object, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	upload, ok := store.uploads[id]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+
+	var (
+		content  bytes.Buffer
+		checksum = md5.New()
+	)
+	for i, completed := range parts {
+		part, ok := upload.Parts[completed.Number]
+		if !ok || !strings.EqualFold(strings.Trim(completed.ETag, `"`), part.ETag) || (i > 0 && completed.Number <= parts[i-1].Number) {
+			return nil, fmt.Errorf("%w: part %d", ErrInvalidPart, completed.Number)
+		}
+		content.Write(part.Content)
+		sum, _ := hex.DecodeString(part.ETag)
+		checksum.Write(sum)
+	}
+
+	obj := upload.Object
+	obj.SetContent(content.Bytes())
+	obj.ETag = hex.EncodeToString(checksum.Sum(nil)) + "-" + strconv.Itoa(len(parts))
+
+	store.bucket(upload.Bucket)[obj.Key] = &obj
+	delete(store.uploads, id)
+	return &obj, nil
+}
+
+// AbortUpload abort the multipart upload, uploaded parts are removed
+func (store *Store) AbortUpload(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.uploads[id]; !ok {
+		return ErrNoSuchUpload
+	}
+	delete(store.uploads, id)
+	return nil
+}
+
+// ParseRange parse Range header with a single range, like "bytes=0-99", "bytes=100-" or "bytes=-100",
+// returns false if the range is invalid or not satisfiable
+func ParseRange(header string, size int64) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	startValue, endValue, _ := strings.Cut(spec, "-")
+	if startValue == "" { // suffix range
+		n, err := strconv.ParseInt(endValue, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		return max(size-n, 0), size - 1, true
+	}
+
+	start, err := strconv.ParseInt(startValue, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+
+	end = size - 1
+	if endValue != "" {
+		if end, err = strconv.ParseInt(endValue, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end, true
+}
@@ -0,0 +1,534 @@
+package objectstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect differences between S3 compatible XML APIs of cloud storages
+type Dialect struct {
+	// Name used in request IDs of responses, e.g. "fakes3"
+	Name string
+	// HeaderPrefix prefix of vendor headers in canonical format, e.g. "X-Amz-", "X-Oss-", "X-Cos-"
+	HeaderPrefix string
+	// ACLHeader header of object's canned ACL, e.g. "X-Amz-Acl"
+	ACLHeader string
+	// Bucket serve the bucket only like virtual hosted style endpoints, paths of requests are keys,
+	// if blank, the first part of paths is the bucket
+	Bucket string
+	// CopySource parse the copy source header into bucket and key, default to "bucket/key" with escaped key
+	CopySource func(header string) (bucket, key string, err error)
+}
+
+// Handler handle object, list and multipart requests of S3 compatible XML APIs with a store, signatures are not verified
+type Handler struct {
+	*Store
+	Dialect Dialect
+	// DenyDelete deny deleting matched objects with AccessDenied errors, could be used to test failures of batch deletes
+	DenyDelete func(bucket, key string) bool
+	// IgnoreRange respond to Range requests with the whole object, like servers or proxies don't support ranges
+	IgnoreRange bool
+}
+
+// NewHandler initialize a handler of dialect with a new store
+func NewHandler(dialect Dialect) *Handler {
+	return &Handler{Store: New(), Dialect: dialect}
+}
+
+func (handler *Handler) header(name string) string {
+	return handler.Dialect.HeaderPrefix + name
+}
+
+// ServeHTTP route requests to operations
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	bucket, key := handler.Dialect.Bucket, strings.TrimPrefix(req.URL.Path, "/")
+	if bucket == "" {
+		bucket, key, _ = strings.Cut(key, "/")
+	}
+	query := req.URL.Query()
+
+	if bucket == "" {
+		handler.writeError(w, http.StatusBadRequest, "InvalidBucketName", "bucket is required")
+		return
+	}
+
+	switch {
+	case key == "" && req.Method == http.MethodGet:
+		handler.listObjects(w, bucket, query)
+	case key == "" && req.Method == http.MethodPost && query.Has("delete"):
+		handler.deleteObjects(w, req, bucket, query)
+	case key == "":
+		handler.writeError(w, http.StatusNotImplemented, "NotImplemented", "bucket operation is not supported")
+	case req.Method == http.MethodPost && query.Has("uploads"):
+		id := handler.CreateUpload(bucket, handler.newObject(key, req.Header))
+		WriteXML(w, http.StatusOK, initiateMultipartResult{Bucket: bucket, Key: key, UploadID: id})
+	case query.Has("uploadId"):
+		handler.handleMultipart(w, req, bucket, key, query)
+	case req.Method == http.MethodPut && req.Header.Get(handler.header("Copy-Source")) != "":
+		handler.copyObject(w, req, bucket, key)
+	case req.Method == http.MethodPut:
+		content, err := ReadBody(req)
+		if err != nil {
+			handler.writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+
+		obj := handler.newObject(key, req.Header)
+		obj.SetContent(content)
+		handler.Put(bucket, obj)
+		w.Header().Set("ETag", `"`+obj.ETag+`"`)
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		handler.getObject(w, req, bucket, key)
+	case req.Method == http.MethodDelete:
+		if handler.DenyDelete != nil && handler.DenyDelete(bucket, key) {
+			handler.writeError(w, http.StatusForbidden, "AccessDenied", "Access Denied")
+			return
+		}
+		handler.Delete(bucket, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		handler.writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method is not allowed")
+	}
+}
+
+// newObject initialize an object with headers of the request
+func (handler *Handler) newObject(key string, header http.Header) *Object {
+	obj := &Object{
+		Key:          key,
+		ContentType:  header.Get("Content-Type"),
+		CacheControl: header.Get("Cache-Control"),
+		ACL:          header.Get(handler.Dialect.ACLHeader),
+		Metadata:     map[string]string{},
+	}
+
+	metaPrefix := handler.header("Meta-")
+	for name, values := range header {
+		if strings.HasPrefix(name, metaPrefix) && len(values) > 0 {
+			obj.Metadata[strings.ToLower(strings.TrimPrefix(name, metaPrefix))] = values[0]
+		}
+	}
+	return obj
+}
+
+func (handler *Handler) getObject(w http.ResponseWriter, req *http.Request, bucket, key string) {
+	obj, ok := handler.Get(bucket, key)
+	if !ok {
+		if req.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		handler.writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	header := w.Header()
+	header.Set("ETag", `"`+obj.ETag+`"`)
+	header.Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	header.Set("Accept-Ranges", "bytes")
+	if obj.ContentType != "" {
+		header.Set("Content-Type", obj.ContentType)
+	}
+	if obj.CacheControl != "" {
+		header.Set("Cache-Control", obj.CacheControl)
+	}
+	for name, value := range obj.Metadata {
+		header.Set(handler.header("Meta-")+name, value)
+	}
+	query := req.URL.Query()
+	for param, name := range map[string]string{"response-content-type": "Content-Type", "response-content-disposition": "Content-Disposition"} {
+		if value := query.Get(param); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	content, status := obj.Content, http.StatusOK
+	if rangeHeader := req.Header.Get("Range"); rangeHeader != "" && !handler.IgnoreRange {
+		start, end, ok := ParseRange(rangeHeader, int64(len(content)))
+		if !ok {
+			handler.writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable")
+			return
+		}
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		content, status = content[start:end+1], http.StatusPartialContent
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(status)
+	if req.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+func (handler *Handler) copyObject(w http.ResponseWriter, req *http.Request, bucket, key string) {
+	parse := handler.Dialect.CopySource
+	if parse == nil {
+		parse = func(header string) (string, string, error) {
+			source, err := url.PathUnescape(header)
+			source, _, _ = strings.Cut(source, "?versionId=")
+			bucket, key, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+			return bucket, key, err
+		}
+	}
+
+	sourceBucket, sourceKey, err := parse(req.Header.Get(handler.header("Copy-Source")))
+	if err != nil {
+		handler.writeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+
+	from, ok := handler.Get(sourceBucket, sourceKey)
+	if !ok {
+		handler.writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	obj := *from
+	obj.Key, obj.LastModified = key, time.Now()
+	if acl := req.Header.Get(handler.Dialect.ACLHeader); acl != "" {
+		obj.ACL = acl
+	}
+	if strings.EqualFold(req.Header.Get(handler.header("Metadata-Directive")), "REPLACE") {
+		replaced := handler.newObject(key, req.Header)
+		obj.ContentType, obj.CacheControl, obj.Metadata = replaced.ContentType, replaced.CacheControl, replaced.Metadata
+	}
+	handler.Put(bucket, &obj)
+
+	WriteXML(w, http.StatusOK, copyObjectResult{ETag: `"` + obj.ETag + `"`, LastModified: formatTime(obj.LastModified)})
+}
+
+// listObjects list objects with ListObjectsV2 if list-type is 2, otherwise with ListObjects (v1)
+func (handler *Handler) listObjects(w http.ResponseWriter, bucket string, query url.Values) {
+	options := ListOptions{Prefix: query.Get("prefix"), Delimiter: query.Get("delimiter"), MaxKeys: 1000}
+	if value := query.Get("max-keys"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 && n < options.MaxKeys {
+			options.MaxKeys = n
+		}
+	}
+
+	v2 := query.Get("list-type") == "2"
+	if v2 {
+		options.Marker = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			options.Marker = token
+		}
+	} else {
+		options.Marker = query.Get("marker")
+	}
+
+	// keys are escaped if requested, so keys with characters not allowed in XML could be listed
+	encode := func(s string) string { return s }
+	if query.Get("encoding-type") == "url" {
+		encode = url.QueryEscape
+	}
+
+	listed := handler.List(bucket, options)
+	result := listBucketResult{
+		Name:        bucket,
+		Prefix:      encode(options.Prefix),
+		Delimiter:   encode(options.Delimiter),
+		MaxKeys:     options.MaxKeys,
+		IsTruncated: listed.IsTruncated,
+		KeyCount:    len(listed.Objects) + len(listed.CommonPrefixes),
+	}
+	if query.Has("encoding-type") {
+		result.EncodingType = query.Get("encoding-type")
+	}
+	if v2 {
+		result.StartAfter = encode(query.Get("start-after"))
+		result.ContinuationToken = query.Get("continuation-token")
+		result.NextContinuationToken = encode(listed.NextMarker)
+	} else {
+		result.Marker = encode(options.Marker)
+		result.NextMarker = encode(listed.NextMarker)
+	}
+
+	for _, obj := range listed.Objects {
+		result.Contents = append(result.Contents, listEntry{
+			Key:          encode(obj.Key),
+			LastModified: formatTime(obj.LastModified),
+			ETag:         `"` + obj.ETag + `"`,
+			Size:         len(obj.Content),
+			StorageClass: "STANDARD",
+		})
+	}
+	for _, prefix := range listed.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: encode(prefix)})
+	}
+	WriteXML(w, http.StatusOK, result)
+}
+
+func (handler *Handler) deleteObjects(w http.ResponseWriter, req *http.Request, bucket string, query url.Values) {
+	var input deleteInput
+	if content, err := ReadBody(req); err != nil {
+		handler.writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	} else if err := xml.Unmarshal(content, &input); err != nil {
+		handler.writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	if len(input.Objects) > 1000 {
+		handler.writeError(w, http.StatusBadRequest, "MalformedXML", "at most 1000 objects could be deleted in a request")
+		return
+	}
+
+	var result deleteResult
+	for _, obj := range input.Objects {
+		key := obj.Key
+		if query.Get("encoding-type") == "url" {
+			key, result.EncodingType = url.QueryEscape(key), "url"
+		}
+
+		if handler.DenyDelete != nil && handler.DenyDelete(bucket, obj.Key) {
+			result.Errors = append(result.Errors, deleteError{Key: key, Code: "AccessDenied", Message: "Access Denied"})
+			continue
+		}
+
+		handler.Delete(bucket, obj.Key)
+		if !input.Quiet {
+			result.Deleted = append(result.Deleted, deletedObject{Key: key})
+		}
+	}
+	WriteXML(w, http.StatusOK, result)
+}
+
+func (handler *Handler) handleMultipart(w http.ResponseWriter, req *http.Request, bucket, key string, query url.Values) {
+	id := query.Get("uploadId")
+	if upload, ok := handler.Upload(id); !ok || upload.Bucket != bucket || upload.Object.Key != key {
+		handler.writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil || partNumber < 1 || partNumber > 10000 {
+			handler.writeError(w, http.StatusBadRequest, "InvalidArgument", "part number must be between 1 and 10000")
+			return
+		}
+
+		content, err := ReadBody(req)
+		if err != nil {
+			handler.writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+
+		part, err := handler.UploadPart(id, partNumber, content)
+		if err != nil {
+			handler.writeError(w, http.StatusNotFound, "NoSuchUpload", err.Error())
+			return
+		}
+		w.Header().Set("ETag", `"`+part.ETag+`"`)
+	case http.MethodGet:
+		upload, _ := handler.Upload(id)
+		parts, _ := handler.ListParts(id)
+
+		result := listPartsResult{Bucket: bucket, Key: key, UploadID: id, MaxParts: 1000}
+		for _, part := range parts {
+			obj := upload.Parts[part.Number]
+			result.Parts = append(result.Parts, partEntry{PartNumber: part.Number, ETag: `"` + part.ETag + `"`, Size: len(obj.Content), LastModified: formatTime(obj.LastModified)})
+		}
+		WriteXML(w, http.StatusOK, result)
+	case http.MethodDelete:
+		handler.AbortUpload(id)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		var input completeMultipartInput
+		if content, err := ReadBody(req); err != nil {
+			handler.writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		} else if err := xml.Unmarshal(content, &input); err != nil {
+			handler.writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+			return
+		}
+
+		var parts []Part
+		for _, part := range input.Parts {
+			parts = append(parts, Part{Number: part.PartNumber, ETag: part.ETag})
+		}
+
+		obj, err := handler.CompleteUpload(id, parts)
+		if errors.Is(err, ErrInvalidPart) {
+			handler.writeError(w, http.StatusBadRequest, "InvalidPart", err.Error())
+			return
+		} else if err != nil {
+			handler.writeError(w, http.StatusNotFound, "NoSuchUpload", err.Error())
+			return
+		}
+
+		WriteXML(w, http.StatusOK, completeMultipartResult{Location: req.URL.Path, Bucket: bucket, Key: key, ETag: `"` + obj.ETag + `"`})
+	default:
+		handler.writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method is not allowed")
+	}
+}
+
+func (handler *Handler) writeError(w http.ResponseWriter, status int, code, message string) {
+	WriteError(w, status, code, message, handler.Dialect.Name)
+}
+
+// ReadBody read body of the request, aws-chunked bodies sent by AWS SDK for streaming uploads are decoded
+func ReadBody(req *http.Request) ([]byte, error) {
+	if !strings.Contains(req.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(req.Body)
+	}
+
+	var (
+		content bytes.Buffer
+		reader  = bufio.NewReader(req.Body)
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("objectstore: invalid aws-chunked body: %w", err)
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("objectstore: invalid aws-chunked size %q", sizeHex)
+		}
+		if size == 0 {
+			return content.Bytes(), nil // trailers are ignored
+		}
+
+		if _, err := io.CopyN(&content, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil { // \r\n
+			return nil, err
+		}
+	}
+}
+
+// WriteXML write value as XML response
+func WriteXML(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(value)
+}
+
+// WriteError write S3 style XML error response
+func WriteError(w http.ResponseWriter, status int, code, message, requestID string) {
+	WriteXML(w, status, errorResponse{Code: code, Message: message, RequestID: requestID})
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string
+	Message   string
+	RequestID string `xml:"RequestId"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string
+	Prefix                string
+	Delimiter             string `xml:",omitempty"`
+	EncodingType          string `xml:",omitempty"`
+	Marker                string `xml:",omitempty"`
+	NextMarker            string `xml:",omitempty"`
+	StartAfter            string `xml:",omitempty"`
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	MaxKeys               int
+	KeyCount              int
+	IsTruncated           bool
+	Contents              []listEntry
+	CommonPrefixes        []commonPrefix
+}
+
+type listEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	StorageClass string
+}
+
+type commonPrefix struct {
+	Prefix string
+}
+
+type copyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	ETag         string
+	LastModified string
+}
+
+type deleteInput struct {
+	Quiet   bool
+	Objects []struct {
+		Key string
+	} `xml:"Object"`
+}
+
+type deleteResult struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeleteResult"`
+	EncodingType string   `xml:",omitempty"`
+	Deleted      []deletedObject
+	Errors       []deleteError `xml:"Error"`
+}
+
+type deletedObject struct {
+	Key string
+}
+
+type deleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+type initiateMultipartResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+	Bucket   string
+	Key      string
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartInput struct {
+	Parts []struct {
+		PartNumber int
+		ETag       string
+	} `xml:"Part"`
+}
+
+type completeMultipartResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+	Location string
+	Bucket   string
+	Key      string
+	ETag     string
+}
+
+type listPartsResult struct {
+	XMLName     xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListPartsResult"`
+	Bucket      string
+	Key         string
+	UploadID    string `xml:"UploadId"`
+	MaxParts    int
+	IsTruncated bool
+	Parts       []partEntry `xml:"Part"`
+}
+
+type partEntry struct {
+	PartNumber   int
+	LastModified string
+	ETag         string
+	Size         int
+}
@@ -1,11 +1,16 @@
 package tests
 
 import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"os"
-	"path/filepath"
+	"io"
+	"math/rand"
+	"path"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -13,124 +18,434 @@ import (
 	"github.com/qor/oss"
 )
 
+//go:embed sample.txt
+var sample []byte
+
+// TestAll run conformance tests against storage, each operation is tested in a subtest, optional capabilities are
+// tested if the storage implemented them. Files are saved under a random directory, which is cleaned up after tests
 func TestAll(storage oss.StorageInterface, t *testing.T) {
-	randomPath := strings.Replace(time.Now().Format("20060102150506.000"), ".", "", -1)
-	fmt.Printf("testing file in %v\n", filepath.Join(storage.GetEndpoint(), randomPath))
-
-	fileName := "/" + filepath.Join(randomPath, "sample.txt")
-	fileName2 := "/" + filepath.Join(randomPath, "sample2", "sample.txt")
-	exceptObjects := 2
-	sampleFile, _ := filepath.Abs("../tests/sample.txt")
-
-	// Put file
-	if file, err := os.Open(sampleFile); err == nil {
-		if object, err := storage.Put(fileName, file); err != nil {
-			t.Errorf("No error should happen when save sample file, but got %v", err)
-		} else if object.Path == "" || object.StorageInterface == nil {
-			t.Errorf("returned object should necessary information")
+	dir := testDir(t, storage)
+
+	t.Run("PutAndGet", func(t *testing.T) { testPutAndGet(t, storage, dir+"/put") })
+	t.Run("PutStream", func(t *testing.T) { testPutStream(t, storage, dir+"/stream") })
+	t.Run("Overwrite", func(t *testing.T) { testOverwrite(t, storage, dir+"/overwrite") })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, storage, dir+"/delete") })
+	t.Run("List", func(t *testing.T) { testList(t, storage, dir+"/list") })
+	t.Run("GetURL", func(t *testing.T) { testGetURL(t, storage, dir+"/url") })
+
+	t.Run("Context", func(t *testing.T) { testContext(t, storage, dir+"/context") })
+	t.Run("Stat", func(t *testing.T) { testStat(t, storage, dir+"/stat") })
+	t.Run("GetRange", func(t *testing.T) { testGetRange(t, storage, dir+"/range") })
+	t.Run("ListPage", func(t *testing.T) { testListPage(t, storage, dir+"/page") })
+	t.Run("CopyAndMove", func(t *testing.T) { testCopyAndMove(t, storage, dir+"/copy") })
+	t.Run("SignedURL", func(t *testing.T) { testSignedURL(t, storage, dir+"/signed") })
+	t.Run("DeleteMany", func(t *testing.T) { testDeleteMany(t, storage, dir+"/batch") })
+	t.Run("Multipart", func(t *testing.T) {
+		uploader, ok := storage.(oss.MultipartUploader)
+		if !ok {
+			t.Skip("storage doesn't implement oss.MultipartUploader")
 		}
-	} else {
-		t.Errorf("No error should happen when opem sample file, but got %v", err)
+		testMultipart(t, storage, uploader, dir+"/multipart")
+	})
+}
+
+// TestMultipart test uploader which uploads files into storage, for storages implement oss.MultipartUploader with other types,
+// e.g. client.Multipart() of Aliyun
+func TestMultipart(storage oss.StorageInterface, uploader oss.MultipartUploader, t *testing.T) {
+	testMultipart(t, storage, uploader, testDir(t, storage)+"/multipart")
+}
+
+// testDir get a new directory to test files in, files in it are deleted after the test
+func testDir(t *testing.T, storage oss.StorageInterface) string {
+	dir := "/" + strings.Replace(time.Now().Format("20060102150405.000"), ".", "", -1) + fmt.Sprint(rand.Intn(1000))
+	t.Logf("testing files in %v", path.Join(storage.GetEndpoint(), dir))
+	t.Cleanup(func() { cleanup(storage, dir) })
+	return dir
+}
+
+// binary content contains all byte values, to check it is saved without encoding
+func binary() []byte {
+	content := make([]byte, 1024)
+	for i := range content {
+		content[i] = byte(i)
 	}
+	return content
+}
 
-	// Put file again
-	if file, err := storage.GetStream(fileName); err == nil {
-		if object, err := storage.Put(fileName, file); err != nil {
-			t.Errorf("No error should happen when save sample file, but got %v", err)
-		} else if object.Path == "" || object.StorageInterface == nil {
-			t.Errorf("returned object should necessary information")
-		}
-	} else {
-		t.Errorf("No error should happen when opem sample file, but got %v", err)
+func testPutAndGet(t *testing.T, storage oss.StorageInterface, dir string) {
+	for name, sample := range map[string]struct {
+		path    string
+		content []byte
+	}{
+		"Text":    {dir + "/sample.txt", sample},
+		"Empty":   {dir + "/empty.txt", []byte{}},
+		"Binary":  {dir + "/binary.bin", binary()},
+		"Unicode": {dir + "/中文 目录/файл 1.txt", []byte("unicode")},
+		"Nested":  {dir + "/a/b/c/d/nested.txt", []byte("nested")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			object, err := storage.Put(sample.path, bytes.NewReader(sample.content))
+			if err != nil {
+				t.Fatalf("No error should happen when put file, but got %v", err)
+			}
+			if object.Path == "" || object.StorageInterface == nil {
+				t.Errorf("Returned object should contain path and storage, but got %+v", object)
+			}
+
+			if file, err := storage.Get(sample.path); err != nil {
+				t.Errorf("No error should happen when get file, but got %v", err)
+			} else {
+				content, _ := io.ReadAll(file)
+				file.Close()
+				if !bytes.Equal(content, sample.content) {
+					t.Errorf("Got file should contain %q, but got %q", sample.content, content)
+				}
+			}
+
+			expectContent(t, storage, sample.path, sample.content)
+		})
+	}
+}
+
+func testPutStream(t *testing.T, storage oss.StorageInterface, dir string) {
+	// reader with unknown size
+	if _, err := storage.Put(dir+"/stream.txt", io.MultiReader(bytes.NewReader(sample))); err != nil {
+		t.Fatalf("No error should happen when put stream, but got %v", err)
+	}
+	expectContent(t, storage, dir+"/stream.txt", sample)
+
+	// put file with the stream of itself
+	stream, err := storage.GetStream(dir + "/stream.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when get stream, but got %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := storage.Put(dir+"/stream.txt", stream); err != nil {
+		t.Errorf("No error should happen when put file with its own stream, but got %v", err)
+	}
+	expectContent(t, storage, dir+"/stream.txt", sample)
+}
+
+func testOverwrite(t *testing.T, storage oss.StorageInterface, dir string) {
+	put(t, storage, dir+"/sample.txt", []byte("first version"))
+	put(t, storage, dir+"/sample.txt", []byte("second"))
+	expectContent(t, storage, dir+"/sample.txt", []byte("second"))
+}
+
+func testDelete(t *testing.T, storage oss.StorageInterface, dir string) {
+	put(t, storage, dir+"/sample.txt", sample)
+	put(t, storage, dir+"/sample2.txt", sample)
+
+	if err := storage.Delete(dir + "/sample.txt"); err != nil {
+		t.Errorf("No error should happen when delete file, but got %v", err)
 	}
 
-	if file, err := os.Open(sampleFile); err == nil {
-		if object, err := storage.Put(fileName2, file); err != nil {
-			t.Errorf("No error should happen when save sample file, but got %v", err)
-		} else if object.Path == "" || object.StorageInterface == nil {
-			t.Errorf("returned object should necessary information")
+	if _, err := storage.Get(dir + "/sample.txt"); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Getting deleted file should fail with oss.ErrNotExist, but got %v", err)
+	}
+	if _, err := storage.GetStream(dir + "/sample.txt"); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Getting stream of deleted file should fail with oss.ErrNotExist, but got %v", err)
+	}
+	expectContent(t, storage, dir+"/sample2.txt", sample)
+
+	// storages either ignore missing files or report them with oss.ErrNotExist
+	if err := storage.Delete(dir + "/missing.txt"); err != nil && !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Deleting missing file should succeed or fail with oss.ErrNotExist, but got %v", err)
+	}
+}
+
+func testList(t *testing.T, storage oss.StorageInterface, dir string) {
+	expected := []string{dir + "/a.txt", dir + "/b.txt", dir + "/sub/c.txt"}
+	for _, path := range expected {
+		put(t, storage, path, sample)
+	}
+	put(t, storage, dir+"-sibling/d.txt", sample)
+
+	objects, err := storage.List(strings.TrimPrefix(dir, "/"))
+	if err != nil {
+		t.Fatalf("No error should happen when list objects, but got %v", err)
+	}
+
+	var paths []string
+	for _, object := range objects {
+		paths = append(paths, object.Path)
+		if object.Name != path.Base(object.Path) || object.StorageInterface == nil {
+			t.Errorf("Listed object should contain name and storage, but got %+v", object)
 		}
-	} else {
-		t.Errorf("No error should happen when opem sample file, but got %v", err)
 	}
+	sort.Strings(paths)
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Should list objects %v, but got %v", expected, paths)
+	}
+}
+
+func testGetURL(t *testing.T, storage oss.StorageInterface, dir string) {
+	put(t, storage, dir+"/sample.txt", sample)
 
-	// Get file
-	if file, err := storage.Get(fileName); err != nil {
-		t.Errorf("No error should happen when get sample file, but got %v", err)
+	if url, err := storage.GetURL(dir + "/sample.txt"); err != nil {
+		t.Errorf("No error should happen when get URL, but got %v", err)
+	} else if url == "" {
+		t.Errorf("URL should not be blank")
+	}
+}
+
+func testContext(t *testing.T, storage oss.StorageInterface, dir string) {
+	contextStorage, ok := storage.(oss.ContextStorage)
+	if !ok {
+		t.Skip("storage doesn't implement oss.ContextStorage")
+	}
+
+	put(t, storage, dir+"/sample.txt", sample)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := contextStorage.PutContext(ctx, dir+"/canceled.txt", bytes.NewReader(sample)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Put with canceled context should fail with context.Canceled, but got %v", err)
+	}
+	if _, err := contextStorage.GetStreamContext(ctx, dir+"/sample.txt"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get stream with canceled context should fail with context.Canceled, but got %v", err)
+	}
+	if _, err := contextStorage.ListContext(ctx, strings.TrimPrefix(dir, "/")); !errors.Is(err, context.Canceled) {
+		t.Errorf("List with canceled context should fail with context.Canceled, but got %v", err)
+	}
+	if err := contextStorage.DeleteContext(ctx, dir+"/sample.txt"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Delete with canceled context should fail with context.Canceled, but got %v", err)
+	}
+	expectContent(t, storage, dir+"/sample.txt", sample)
+}
+
+func testStat(t *testing.T, storage oss.StorageInterface, dir string) {
+	stater, ok := storage.(oss.Stater)
+	if !ok {
+		t.Skip("storage doesn't implement oss.Stater")
+	}
+
+	put(t, storage, dir+"/sample.txt", sample)
+	if info, err := stater.Stat(dir + "/sample.txt"); err != nil {
+		t.Errorf("No error should happen when stat file, but got %v", err)
 	} else {
-		if buffer, err := ioutil.ReadAll(file); err != nil {
-			t.Errorf("No error should happen when read downloaded file, but got %v", err)
-		} else if string(buffer) == "sample" {
-			t.Errorf("Downloaded file should contain correct content, but got %v", string(buffer))
+		if info.Size != int64(len(sample)) {
+			t.Errorf("Size should be %v, but got %v", len(sample), info.Size)
+		}
+		if !strings.HasPrefix(info.ContentType, "text/plain") {
+			t.Errorf("Content type should be text/plain, but got %v", info.ContentType)
+		}
+		if info.LastModified == nil {
+			t.Errorf("Last modified time should be returned")
 		}
 	}
 
-	// GetURL
-	if url, err := storage.GetURL(fileName); err != nil {
-		t.Errorf("No error should happen when GetURL for sample file, but got %v", err)
-	} else if strings.HasPrefix(url, "http") {
-		resp, err := http.Get(url)
+	if _, err := stater.Stat(dir + "/missing.txt"); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Stat missing file should fail with oss.ErrNotExist, but got %v", err)
+	}
 
-		if err != nil {
-			t.Errorf("No error should happen when get file with public URL")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := stater.StatContext(ctx, dir+"/sample.txt"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Stat with canceled context should fail with context.Canceled, but got %v", err)
+	}
+}
+
+func testGetRange(t *testing.T, storage oss.StorageInterface, dir string) {
+	getter, ok := storage.(oss.RangeGetter)
+	if !ok {
+		t.Skip("storage doesn't implement oss.RangeGetter")
+	}
+
+	content := []byte(strings.Repeat("0123456789", 10))
+	put(t, storage, dir+"/sample.txt", content)
+	put(t, storage, dir+"/empty.txt", []byte{})
+
+	size := int64(len(content))
+	for _, r := range []struct {
+		path           string
+		offset, length int64
+		expected       []byte
+	}{
+		{dir + "/sample.txt", 0, 5, content[:5]},
+		{dir + "/sample.txt", 5, 5, content[5:10]},
+		{dir + "/sample.txt", 5, -1, content[5:]},
+		{dir + "/sample.txt", size - 3, 10, content[size-3:]},
+		{dir + "/sample.txt", 3, 0, []byte{}},
+		{dir + "/empty.txt", 0, -1, []byte{}},
+	} {
+		if stream, err := getter.GetRange(r.path, r.offset, r.length); err != nil {
+			t.Errorf("No error should happen when get range %v+%v of %v, but got %v", r.offset, r.length, r.path, err)
 		} else {
-			if buffer, err := ioutil.ReadAll(resp.Body); err != nil {
-				t.Errorf("No error should happen when read downloaded file, but got %v", err)
-			} else if string(buffer) == "sample" {
-				t.Errorf("Downloaded file should contain correct content, but got %v", string(buffer))
+			content, err := io.ReadAll(stream)
+			stream.Close()
+			if err != nil || !bytes.Equal(content, r.expected) {
+				t.Errorf("Range %v+%v of %v should be %q, but got %q, %v", r.offset, r.length, r.path, r.expected, content, err)
 			}
 		}
 	}
 
-	// Get stream
-	if stream, err := storage.GetStream(fileName); err != nil {
-		t.Errorf("No error should happen when get sample file, but got %v", err)
-	} else {
-		if buffer, err := ioutil.ReadAll(stream); err != nil {
-			t.Errorf("No error should happen when read downloaded file, but got %v", err)
-		} else if string(buffer) == "sample" {
-			t.Errorf("Downloaded file should contain correct content, but got %v", string(buffer))
-		}
+	if _, err := getter.GetRange(dir+"/sample.txt", size+10, 5); !errors.Is(err, oss.ErrInvalidRange) {
+		t.Errorf("Range beyond the end of file should fail with oss.ErrInvalidRange, but got %v", err)
+	}
+	if _, err := getter.GetRange(dir+"/missing.txt", 0, 5); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Range of missing file should fail with oss.ErrNotExist, but got %v", err)
 	}
+}
 
-	// List
-	if objects, err := storage.List(randomPath); err != nil {
-		t.Errorf("No error should happen when list objects, but got %v", err)
-	} else if len(objects) != exceptObjects {
-		t.Errorf("Should found %v objects, but got %v", exceptObjects, len(objects))
-	} else {
-		var found1, found2 bool
-		for _, object := range objects {
-			if object.Path == fileName {
-				found1 = true
-			}
+func testListPage(t *testing.T, storage oss.StorageInterface, dir string) {
+	if _, ok := storage.(oss.Lister); !ok {
+		t.Skip("storage doesn't implement oss.Lister")
+	}
 
-			if object.Path == fileName2 {
-				found2 = true
-			}
-		}
+	for _, path := range []string{"/c.txt", "/a.txt", "/sub/d.txt", "/b.txt", "/sub/e/f.txt"} {
+		put(t, storage, dir+path, sample)
+	}
+
+	result, err := oss.List(context.Background(), storage, oss.ListOptions{Prefix: dir + "/"})
+	if err != nil {
+		t.Fatalf("No error should happen when list page, but got %v", err)
+	}
+	if paths := objectPaths(result.Objects); !reflect.DeepEqual(paths, []string{dir + "/a.txt", dir + "/b.txt", dir + "/c.txt"}) {
+		t.Errorf("Objects should be listed in lexicographical order, but got %v", paths)
+	}
+	if !reflect.DeepEqual(result.CommonPrefixes, []string{dir + "/sub/"}) {
+		t.Errorf("Objects in sub directory should be grouped into common prefix, but got %v", result.CommonPrefixes)
+	}
+
+	result, err = oss.List(context.Background(), storage, oss.ListOptions{Prefix: dir + "/", StartAfter: dir + "/a.txt", Recursive: true})
+	if err != nil {
+		t.Fatalf("No error should happen when list page, but got %v", err)
+	}
+	expected := []string{dir + "/b.txt", dir + "/c.txt", dir + "/sub/d.txt", dir + "/sub/e/f.txt"}
+	if paths := objectPaths(result.Objects); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Objects after %v should be listed recursively, but got %v", dir+"/a.txt", paths)
+	}
 
-		if !found1 {
-			t.Errorf("Should found uploaded file %v", fileName)
+	var iterated []*oss.Object
+	for object, err := range oss.Objects(context.Background(), storage, oss.ListOptions{Prefix: dir + "/", Recursive: true, MaxKeys: 2}) {
+		if err != nil {
+			t.Fatalf("No error should happen when iterate objects, but got %v", err)
 		}
+		iterated = append(iterated, object)
+	}
+	if paths := objectPaths(iterated); !reflect.DeepEqual(paths, append([]string{dir + "/a.txt"}, expected...)) {
+		t.Errorf("All objects should be iterated page by page in order, but got %v", paths)
+	}
+}
+
+func testCopyAndMove(t *testing.T, storage oss.StorageInterface, dir string) {
+	copier, ok := storage.(oss.Copier)
+	if !ok {
+		t.Skip("storage doesn't implement oss.Copier")
+	}
+
+	put(t, storage, dir+"/sample.txt", sample)
+	if err := copier.Copy(dir+"/sample.txt", dir+"/copied.txt"); err != nil {
+		t.Fatalf("No error should happen when copy file, but got %v", err)
+	}
+	expectContent(t, storage, dir+"/sample.txt", sample)
+	expectContent(t, storage, dir+"/copied.txt", sample)
+
+	if err := copier.Move(dir+"/copied.txt", dir+"/moved/sample.txt"); err != nil {
+		t.Fatalf("No error should happen when move file, but got %v", err)
+	}
+	expectContent(t, storage, dir+"/moved/sample.txt", sample)
+	if _, err := storage.GetStream(dir + "/copied.txt"); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Moved file should not exist, but got %v", err)
+	}
+
+	if err := copier.Copy(dir+"/missing.txt", dir+"/copied.txt"); !errors.Is(err, oss.ErrNotExist) {
+		t.Errorf("Copying missing file should fail with oss.ErrNotExist, but got %v", err)
+	}
+}
+
+func testSignedURL(t *testing.T, storage oss.StorageInterface, dir string) {
+	signer, ok := storage.(oss.URLSigner)
+	if !ok {
+		t.Skip("storage doesn't implement oss.URLSigner")
+	}
+
+	put(t, storage, dir+"/sample.txt", sample)
+	if url, err := signer.GetSignedURL(dir+"/sample.txt", oss.URLOptions{Expires: time.Minute}); err != nil {
+		t.Errorf("No error should happen when get signed URL, but got %v", err)
+	} else if url == "" {
+		t.Errorf("Signed URL should not be blank")
+	}
+}
 
-		if !found2 {
-			t.Errorf("Should found uploaded file %v", fileName2)
+func testDeleteMany(t *testing.T, storage oss.StorageInterface, dir string) {
+	deleter, ok := storage.(oss.BatchDeleter)
+	if !ok {
+		t.Skip("storage doesn't implement oss.BatchDeleter")
+	}
+
+	put(t, storage, dir+"/a.txt", sample)
+	put(t, storage, dir+"/b/c.txt", sample)
+	put(t, storage, dir+"/kept.txt", sample)
+
+	paths := []string{dir + "/a.txt", dir + "/missing.txt", dir + "/b/c.txt", dir + "/b/missing.txt"}
+	result, err := deleter.DeleteManyContext(context.Background(), paths)
+	if err != nil || len(result.Failures) != 0 {
+		t.Fatalf("No error should happen when delete existing and missing files, but got %v, %+v", err, result.Failures)
+	}
+	if !reflect.DeepEqual(result.Deleted, paths) {
+		t.Errorf("All files should be reported as deleted in requested order, but got %v", result.Deleted)
+	}
+
+	for _, path := range []string{dir + "/a.txt", dir + "/b/c.txt"} {
+		if _, err := storage.GetStream(path); !errors.Is(err, oss.ErrNotExist) {
+			t.Errorf("Deleted file %v should not exist, but got %v", path, err)
 		}
 	}
+	expectContent(t, storage, dir+"/kept.txt", sample)
+}
 
-	// Delete
-	if err := storage.Delete(fileName); err != nil {
-		t.Errorf("No error should happen when delete sample file, but got %v", err)
+func testMultipart(t *testing.T, storage oss.StorageInterface, uploader oss.MultipartUploader, dir string) {
+	object, err := oss.UploadMultipart(context.Background(), uploader, dir+"/sample.txt", bytes.NewReader(sample), oss.MultipartOptions{})
+	if err != nil {
+		t.Fatalf("No error should happen when upload file in parts, but got %v", err)
+	}
+	if object.Path != dir+"/sample.txt" {
+		t.Errorf("Uploaded object's path should be %v, but got %v", dir+"/sample.txt", object.Path)
 	}
+	expectContent(t, storage, dir+"/sample.txt", sample)
+}
 
-	// Get file after delete
-	if _, err := storage.Get(fileName); err == nil {
-		t.Errorf("There should be an error when get deleted sample file")
+// put save content into path, the test is stopped if failed
+func put(t *testing.T, storage oss.StorageInterface, path string, content []byte) {
+	t.Helper()
+	if _, err := storage.Put(path, bytes.NewReader(content)); err != nil {
+		t.Fatalf("No error should happen when put %v, but got %v", path, err)
 	}
+}
+
+// expectContent check the file of path contains content
+func expectContent(t *testing.T, storage oss.StorageInterface, path string, content []byte) {
+	t.Helper()
+
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		t.Errorf("No error should happen when get stream of %v, but got %v", path, err)
+		return
+	}
+	defer stream.Close()
+
+	if got, err := io.ReadAll(stream); err != nil {
+		t.Errorf("No error should happen when read stream of %v, but got %v", path, err)
+	} else if !bytes.Equal(got, content) {
+		t.Errorf("%v should contain %q, but got %q", path, content, got)
+	}
+}
+
+func objectPaths(objects []*oss.Object) (paths []string) {
+	for _, object := range objects {
+		paths = append(paths, object.Path)
+	}
+	return paths
+}
 
-	// Get file after delete
-	if _, err := storage.Get(fileName2); err != nil {
-		t.Errorf("Sample file 2 should no been deleted")
+// cleanup delete all files under dir
+func cleanup(storage oss.StorageInterface, dir string) {
+	objects, _ := storage.List(strings.TrimPrefix(dir, "/"))
+	for _, object := range objects {
+		storage.Delete(object.Path)
 	}
 }
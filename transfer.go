@@ -0,0 +1,203 @@
+package oss
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// TransferOptions options for Transfer
+type TransferOptions struct {
+	// Concurrency objects transferred at the same time, default 4
+	Concurrency int
+	// SkipIdentical skip objects exist in destination storage with same size and ETag, both storages need to implement Stater.
+	// If ETags aren't comparable, e.g. file system, multipart uploads or Qiniu, objects are skipped only if the destination isn't older than the source
+	SkipIdentical bool
+	// Rename get path in destination storage from path in source storage, default to the same path
+	Rename func(path string) string
+	// Progress called after each object is transferred, skipped or failed, calls are serialized
+	Progress func(progress TransferProgress)
+}
+
+// TransferProgress progress of a transferred object
+type TransferProgress struct {
+	Path    string
+	Bytes   int64
+	Skipped bool
+	Err     error
+	Result  TransferResult // result of transferred objects so far, including current one
+}
+
+// TransferResult summary of a transfer
+type TransferResult struct {
+	Transferred int
+	Skipped     int
+	Bytes       int64
+	Failures    []TransferFailure
+}
+
+// TransferFailure an object failed to transfer
+type TransferFailure struct {
+	Path string
+	Err  error
+}
+
+// Err get an error joined all failures, nil if no failure
+func (result TransferResult) Err() error {
+	var errs []error
+	for _, failure := range result.Failures {
+		errs = append(errs, fmt.Errorf("transfer %v: %w", failure.Path, failure.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// Transfer copy object of path from src storage to dst storage, if path ends with "/", all objects under it are transferred.
+// Objects are streamed from src to dst, failed objects don't stop the transfer, they are returned in result's Failures and the error
+func Transfer(ctx context.Context, src, dst StorageInterface, path string, options TransferOptions) (*TransferResult, error) {
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultConcurrency
+	}
+	if options.Rename == nil {
+		options.Rename = func(path string) string { return path }
+	}
+
+	var (
+		result    = &TransferResult{}
+		mutex     sync.Mutex
+		waitGroup sync.WaitGroup
+		semaphore = make(chan struct{}, options.Concurrency)
+	)
+
+	transfer := func(path string) {
+		defer func() {
+			<-semaphore
+			waitGroup.Done()
+		}()
+
+		bytes, skipped, err := transferObject(ctx, src, dst, path, options)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch {
+		case err != nil:
+			result.Failures = append(result.Failures, TransferFailure{Path: path, Err: err})
+		case skipped:
+			result.Skipped++
+		default:
+			result.Transferred++
+			result.Bytes += bytes
+		}
+
+		if options.Progress != nil {
+			options.Progress(TransferProgress{Path: path, Bytes: bytes, Skipped: skipped, Err: err, Result: *result})
+		}
+	}
+
+	var listErr error
+	if strings.HasSuffix(path, "/") {
+		for object, err := range Objects(ctx, src, ListOptions{Prefix: path, Recursive: true}) {
+			if err != nil {
+				listErr = err
+				break
+			}
+
+			semaphore <- struct{}{}
+			waitGroup.Add(1)
+			go transfer(object.Path)
+		}
+	} else {
+		semaphore <- struct{}{}
+		waitGroup.Add(1)
+		go transfer(path)
+	}
+
+	waitGroup.Wait()
+
+	if listErr != nil {
+		return result, listErr
+	}
+	return result, result.Err()
+}
+
+// transferObject stream an object from src to dst, returns transferred bytes
+func transferObject(ctx context.Context, src, dst StorageInterface, path string, options TransferOptions) (int64, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, false, err
+	}
+
+	target := options.Rename(path)
+	if options.SkipIdentical && identical(ctx, src, path, dst, target) {
+		return 0, true, nil
+	}
+
+	stream, err := WithContext(src).GetStreamContext(ctx, path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer stream.Close()
+
+	reader := &countReader{Reader: stream}
+	if _, err := WithContext(dst).PutContext(ctx, target, reader); err != nil {
+		return reader.count, false, err
+	}
+	return reader.count, false, nil
+}
+
+// identical check if object of srcPath is same as object of dstPath, they are identical if they have same size and ETag, ETags are
+// case insensitive as Aliyun returns upper case MD5. If ETags aren't comparable, e.g. unknown, multipart ETags or Qiniu hashes,
+// they are identical if they have same size and the destination isn't older than the source
+func identical(ctx context.Context, src StorageInterface, srcPath string, dst StorageInterface, dstPath string) bool {
+	srcStater, ok := src.(Stater)
+	if !ok {
+		return false
+	}
+	dstStater, ok := dst.(Stater)
+	if !ok {
+		return false
+	}
+
+	srcInfo, err := srcStater.StatContext(ctx, srcPath)
+	if err != nil {
+		return false
+	}
+	dstInfo, err := dstStater.StatContext(ctx, dstPath)
+	if err != nil {
+		return false
+	}
+
+	if srcInfo.Size != dstInfo.Size {
+		return false
+	}
+	if strings.EqualFold(srcInfo.ETag, dstInfo.ETag) && srcInfo.ETag != "" {
+		return true
+	}
+	if isMD5(srcInfo.ETag) && isMD5(dstInfo.ETag) {
+		return false
+	}
+	return srcInfo.LastModified != nil && dstInfo.LastModified != nil && !dstInfo.LastModified.Before(*srcInfo.LastModified)
+}
+
+// isMD5 check if ETag is a MD5 in hex, ETags of multipart uploads end with -<parts>
+func isMD5(etag string) bool {
+	if len(etag) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(etag)
+	return err == nil
+}
+
+// countReader count bytes read from reader
+type countReader struct {
+	io.Reader
+	count int64
+}
+
+func (reader *countReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	reader.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,103 @@
+package oss_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/filesystem"
+	"github.com/qor/oss/memory"
+)
+
+func TestTransfer(t *testing.T) {
+	src, dst := filesystem.New(t.TempDir()), filesystem.New(t.TempDir())
+	for _, path := range []string{"/a/1.txt", "/a/b/2.txt", "/c.txt"} {
+		src.Put(path, strings.NewReader(path))
+	}
+
+	var progresses []oss.TransferProgress
+	result, err := oss.Transfer(context.Background(), src, dst, "/a/", oss.TransferOptions{
+		Concurrency:   2,
+		SkipIdentical: true,
+		Rename:        func(path string) string { return "/backup" + path },
+		Progress:      func(progress oss.TransferProgress) { progresses = append(progresses, progress) },
+	})
+	if err != nil {
+		t.Fatalf("No error should happen when transfer objects, but got %v", err)
+	}
+
+	if result.Transferred != 2 || result.Bytes != int64(len("/a/1.txt/a/b/2.txt")) || len(progresses) != 2 {
+		t.Errorf("2 objects should be transferred, but got %+v, %v progresses", result, len(progresses))
+	}
+
+	for _, path := range []string{"/a/1.txt", "/a/b/2.txt"} {
+		if stream, err := dst.GetStream("/backup" + path); err != nil {
+			t.Errorf("No error should happen when get transferred object, but got %v", err)
+		} else if buffer, _ := ioutil.ReadAll(stream); string(buffer) != path {
+			t.Errorf("Transferred object should have same content, but got %v", string(buffer))
+		}
+	}
+
+	if result, _ = oss.Transfer(context.Background(), src, dst, "/a/1.txt", oss.TransferOptions{SkipIdentical: true, Rename: func(path string) string { return "/backup" + path }}); result.Skipped != 1 {
+		t.Errorf("Identical object should be skipped, but got %+v", result)
+	}
+
+	// file system doesn't have ETag, changed object with same size is transferred if the destination is older
+	src.Put("/a/1.txt", strings.NewReader("/A/1.TXT"))
+	os.Chtimes(filepath.Join(dst.Base, "/backup/a/1.txt"), time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+	if result, _ = oss.Transfer(context.Background(), src, dst, "/a/1.txt", oss.TransferOptions{SkipIdentical: true, Rename: func(path string) string { return "/backup" + path }}); result.Transferred != 1 {
+		t.Errorf("Changed object should be transferred, but got %+v", result)
+	}
+
+	result, err = oss.Transfer(context.Background(), src, dst, "/missing.txt", oss.TransferOptions{})
+	if !errors.Is(err, oss.ErrNotExist) || len(result.Failures) != 1 || result.Failures[0].Path != "/missing.txt" {
+		t.Errorf("Failed object should be reported, but got %+v, %v", result, err)
+	}
+}
+
+// etagStorage a storage returns ETag converted by etag from Stat
+type etagStorage struct {
+	*memory.Storage
+	etag func(etag string) string
+}
+
+func (storage etagStorage) StatContext(ctx context.Context, path string) (*oss.ObjectInfo, error) {
+	info, err := storage.Storage.StatContext(ctx, path)
+	if err == nil {
+		info.ETag = storage.etag(info.ETag)
+	}
+	return info, err
+}
+
+func (storage etagStorage) Stat(path string) (*oss.ObjectInfo, error) {
+	return storage.StatContext(context.Background(), path)
+}
+
+func TestTransferSkipIdentical(t *testing.T) {
+	src := memory.New()
+	src.Put("/sample.txt", strings.NewReader("sample"))
+
+	for name, etag := range map[string]func(string) string{
+		"upper case MD5": strings.ToUpper,
+		"multipart":      func(etag string) string { return etag[:31] + "0-2" },
+	} {
+		dst := etagStorage{Storage: memory.New(), etag: etag}
+		dst.Put("/sample.txt", strings.NewReader("sample"))
+
+		if result, _ := oss.Transfer(context.Background(), src, dst, "/sample.txt", oss.TransferOptions{SkipIdentical: true}); result.Skipped != 1 {
+			t.Errorf("Identical object with %v ETag should be skipped, but got %+v", name, result)
+		}
+	}
+
+	dst := etagStorage{Storage: memory.New(), etag: strings.ToUpper}
+	dst.Put("/sample.txt", strings.NewReader("SAMPLE"))
+	if result, _ := oss.Transfer(context.Background(), src, dst, "/sample.txt", oss.TransferOptions{SkipIdentical: true}); result.Transferred != 1 {
+		t.Errorf("Changed object with different MD5 should be transferred, but got %+v", result)
+	}
+}
@@ -0,0 +1,59 @@
+package oss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURLExpires default expiry of signed URLs
+const DefaultURLExpires = time.Hour
+
+// URLSigner storages that could generate pre-signed URLs
+type URLSigner interface {
+	GetSignedURL(path string, options URLOptions) (string, error)
+	GetSignedURLContext(ctx context.Context, path string, options URLOptions) (string, error)
+}
+
+// URLOptions options to generate pre-signed URLs
+type URLOptions struct {
+	// Expires how long the URL is valid, default 1 hour
+	Expires time.Duration
+	// Method HTTP method allowed with the URL, default GET, use PUT to upload files directly from browsers
+	Method string
+	// ResponseContentDisposition override Content-Disposition header of the response, e.g. `attachment; filename="report.pdf"`
+	ResponseContentDisposition string
+	// ResponseContentType override Content-Type header of the response
+	ResponseContentType string
+}
+
+// GetExpires get expiry of the URL
+func (options URLOptions) GetExpires() time.Duration {
+	if options.Expires <= 0 {
+		return DefaultURLExpires
+	}
+	return options.Expires
+}
+
+// GetMethod get upper cased HTTP method of the URL
+func (options URLOptions) GetMethod() string {
+	if options.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(options.Method)
+}
+
+// GetSignedURL generate a pre-signed URL with storage if it implemented URLSigner,
+// otherwise its public URL is returned for GET requests without response overrides
+func GetSignedURL(ctx context.Context, storage StorageInterface, path string, options URLOptions) (string, error) {
+	if signer, ok := storage.(URLSigner); ok {
+		return signer.GetSignedURLContext(ctx, path, options)
+	}
+
+	if options.GetMethod() != http.MethodGet || options.ResponseContentDisposition != "" || options.ResponseContentType != "" {
+		return "", fmt.Errorf("oss: storage doesn't support signed URL with method %v or response overrides", options.GetMethod())
+	}
+	return WithContext(storage).GetURLContext(ctx, path)
+}
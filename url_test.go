@@ -0,0 +1,21 @@
+package oss_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qor/oss"
+	"github.com/qor/oss/filesystem"
+)
+
+func TestGetSignedURL(t *testing.T) {
+	storage := filesystem.New(t.TempDir())
+
+	if url, err := oss.GetSignedURL(context.Background(), storage, "/sample.txt", oss.URLOptions{}); err != nil || url != "/sample.txt" {
+		t.Errorf("Storage doesn't support signed URL should return its URL for GET requests, but got %v, %v", url, err)
+	}
+
+	if _, err := oss.GetSignedURL(context.Background(), storage, "/sample.txt", oss.URLOptions{Method: "PUT"}); err == nil {
+		t.Errorf("Storage doesn't support signed URL should fail for PUT requests")
+	}
+}